@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nikitalystsev/BookSmart-tech-ui/config"
+	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/requesters"
+	"log/slog"
+	"os"
+)
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logLevel := slog.LevelInfo
+	if cfg.Debug {
+		logLevel = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+	requester, err := requesters.NewRequesterFromConfig(cfg, input.NewStdin(), client.WithLogger(logger))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	// команды проверяют API первым же запросом, поэтому help и ошибки
+	// вызова не зависят от доступности сервера
+	if len(cfg.Args) > 0 {
+		os.Exit(requester.Exec(ctx, cfg.Args))
+	}
+
+	if !cfg.SkipHealthCheck {
+		if err = requester.HealthCheck(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(requesters.ExitUnavailable)
+		}
+	}
+
+	requester.RunContext(ctx)
+}
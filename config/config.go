@@ -0,0 +1,560 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Порядок применения настроек (каждый следующий источник перекрывает предыдущий):
+//  1. значения по умолчанию
+//  2. конфигурационный файл (--config, BOOKSMART_CONFIG или <user config dir>/booksmart/config.json)
+//  3. переменные окружения BOOKSMART_*
+//  4. флаги командной строки
+const (
+	envConfig          = "BOOKSMART_CONFIG"
+	envAPIURL          = "BOOKSMART_API_URL"
+	envAPIScheme       = "BOOKSMART_API_SCHEME"
+	envAPIHost         = "BOOKSMART_API_HOST"
+	envAPIPort         = "BOOKSMART_API_PORT"
+	envAPIPrefix       = "BOOKSMART_API_PREFIX"
+	envCAFile          = "BOOKSMART_CA_FILE"
+	envRequestTimeout  = "BOOKSMART_REQUEST_TIMEOUT"
+	envAccessTokenTTL  = "BOOKSMART_ACCESS_TOKEN_TTL"
+	envRefreshTokenTTL = "BOOKSMART_REFRESH_TOKEN_TTL"
+	envRetries         = "BOOKSMART_RETRIES"
+	envDebug           = "BOOKSMART_DEBUG"
+
+	envSessionStore      = "BOOKSMART_SESSION_STORE"
+	envSessionFile       = "BOOKSMART_SESSION_FILE"
+	envSessionPassphrase = "BOOKSMART_SESSION_PASSPHRASE"
+	envProfile           = "BOOKSMART_PROFILE"
+	envOutput            = "BOOKSMART_OUTPUT"
+	envPageSize          = "BOOKSMART_PAGE_SIZE"
+	envDueSoonDays       = "BOOKSMART_DUE_SOON_DAYS"
+	envPhone             = "BOOKSMART_PHONE"
+	envPassword          = "BOOKSMART_PASSWORD"
+)
+
+// APIConfig - составные части адреса BookSmart-web-api
+type APIConfig struct {
+	Scheme string `json:"scheme"`
+	Host   string `json:"host"`
+	Port   string `json:"port"`
+	Prefix string `json:"prefix"`
+}
+
+// BaseURL собирает адрес API из его частей
+func (c APIConfig) BaseURL() string {
+	host := c.Host
+	if c.Port != "" {
+		host = net.JoinHostPort(c.Host, c.Port)
+	}
+
+	u := url.URL{Scheme: c.Scheme, Host: host, Path: c.Prefix}
+
+	return strings.TrimRight(u.String(), "/")
+}
+
+// Validate проверяет, что из частей получается корректный адрес
+func (c APIConfig) Validate() error {
+	if c.Scheme != "http" && c.Scheme != "https" {
+		return fmt.Errorf("unsupported API scheme %q", c.Scheme)
+	}
+	if c.Host == "" {
+		return errors.New("API host is empty")
+	}
+	if c.Port != "" {
+		if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid API port %q", c.Port)
+		}
+	}
+	if c.Prefix != "" && !strings.HasPrefix(c.Prefix, "/") {
+		return fmt.Errorf("API prefix %q must start with /", c.Prefix)
+	}
+
+	return nil
+}
+
+// Config - итоговые настройки приложения
+type Config struct {
+	API             APIConfig
+	Transport       client.TransportConfig
+	Retry           client.RetryPolicy
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// RefreshMargin - за сколько до истечения токена доступа его обновлять,
+	// RefreshWarning - за сколько до истечения сессии предупреждать читателя,
+	// если обновить токены не удается
+	RefreshMargin   time.Duration
+	RefreshWarning  time.Duration
+	SkipHealthCheck bool
+	Debug           bool
+
+	// SessionStore включает сохранение сессии между запусками в SessionFile.
+	// Парольная фраза берется только из окружения, иначе запрашивается при старте
+	SessionStore      bool
+	SessionFile       string
+	SessionPassphrase string
+
+	// Profile - профиль, выбранный при старте; профили хранятся в ProfilesFile
+	Profile      string
+	ProfilesFile string
+
+	// FavoritesFile - локальный список избранного (сервер его не отдает)
+	FavoritesFile string
+
+	// Output - формат вывода списков и карточек
+	Output output.Format
+
+	// PageSize - сколько книг на странице каталога (от 1 до MaxPageSize)
+	PageSize uint
+
+	// DueSoonDays - за сколько дней до срока возврата напоминать о брони,
+	// ReminderInterval - как часто в течение сессии проверять брони
+	DueSoonDays      uint
+	ReminderInterval time.Duration
+
+	// Phone и Password - учетные данные для неинтерактивных команд (только из окружения)
+	Phone    string
+	Password string
+
+	// Args - команда и ее аргументы после флагов; пусто - интерактивное меню
+	Args []string
+}
+
+// fileConfig - формат конфигурационного файла (JSON)
+type fileConfig struct {
+	API              APIConfig `json:"api"`
+	URL              string    `json:"url"`
+	CAFile           string    `json:"ca_file"`
+	Insecure         bool      `json:"insecure"`
+	RequestTimeout   string    `json:"request_timeout"`
+	AccessTokenTTL   string    `json:"access_token_ttl"`
+	RefreshTokenTTL  string    `json:"refresh_token_ttl"`
+	RefreshMargin    string    `json:"refresh_margin"`
+	RefreshWarning   string    `json:"refresh_warning"`
+	ReminderInterval string    `json:"reminder_interval"`
+	Retry            struct {
+		MaxAttempts     int    `json:"max_attempts"`
+		BaseDelay       string `json:"base_delay"`
+		MaxDelay        string `json:"max_delay"`
+		IdempotencyKeys bool   `json:"idempotency_keys"`
+	} `json:"retry"`
+	Debug        bool   `json:"debug"`
+	SessionStore bool   `json:"session_store"`
+	SessionFile  string `json:"session_file"`
+	Profile      string `json:"profile"`
+	Output       string `json:"output"`
+	PageSize     uint   `json:"page_size"`
+	DueSoonDays  uint   `json:"due_soon_days"`
+}
+
+// Default возвращает настройки по умолчанию: локальный API на порту 8000
+func Default() Config {
+	return Config{
+		API: APIConfig{
+			Scheme: "http",
+			Host:   "localhost",
+			Port:   "8000",
+		},
+		Transport:        client.DefaultTransportConfig(),
+		Retry:            client.DefaultRetryPolicy(),
+		AccessTokenTTL:   15 * time.Minute,
+		RefreshTokenTTL:  30 * 24 * time.Hour,
+		RefreshMargin:    30 * time.Second,
+		RefreshWarning:   10 * time.Minute,
+		Output:           output.Table,
+		PageSize:         10,
+		DueSoonDays:      3,
+		ReminderInterval: 30 * time.Minute,
+	}
+}
+
+// Load собирает настройки из всех источников в порядке их приоритета
+func Load(args []string) (Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("booksmart", flag.ContinueOnError)
+	var (
+		configPath      = fs.String("config", "", "path to JSON config file")
+		apiURL          = fs.String("api-url", "", "full API base URL, e.g. https://books.example.com/booksmart")
+		scheme          = fs.String("api-scheme", "", "API scheme (http or https)")
+		host            = fs.String("api-host", "", "API host")
+		port            = fs.String("api-port", "", "API port")
+		prefix          = fs.String("api-prefix", "", "API path prefix, e.g. /booksmart")
+		caFile          = fs.String("ca-file", "", "PEM bundle with additional trusted CAs")
+		insecure        = fs.Bool("insecure", false, "skip TLS certificate verification")
+		requestTimeout  = fs.Duration("timeout", 0, "timeout for a single API request")
+		accessTokenTTL  = fs.Duration("access-token-ttl", 0, "access token lifetime")
+		refreshTokenTTL = fs.Duration("refresh-token-ttl", 0, "refresh token lifetime")
+		refreshMargin   = fs.Duration("refresh-margin", 0, "refresh the access token this long before it expires")
+		refreshWarning  = fs.Duration("refresh-warning", 0, "warn this long before the session expires")
+		skipHealthCheck = fs.Bool("skip-health-check", false, "do not check the API at startup")
+		retries         = fs.Int("retries", 0, "total attempts for a request that failed transiently")
+		retryBaseDelay  = fs.Duration("retry-base-delay", 0, "initial delay between retries")
+		retryMaxDelay   = fs.Duration("retry-max-delay", 0, "maximum delay between retries")
+		idempotencyKeys = fs.Bool("idempotency-keys", false, "attach Idempotency-Key to creating POSTs so they can be retried")
+		debug           = fs.Bool("debug", false, "log every API request attempt to stderr")
+		sessionStore    = fs.Bool("session-store", false, "keep the session between runs in an encrypted file")
+		sessionFile     = fs.String("session-file", "", "path to the saved session file")
+		profileName     = fs.String("profile", "", "profile to use at startup")
+		outputFormat    = fs.String("output", "", "output format: table, json, ndjson, csv, yaml or markdown")
+		pageSize        = fs.Uint("page-size", 0, "books per catalog page")
+		dueSoonDays     = fs.Uint("due-soon-days", 0, "remind about reservations due within this many days")
+		reminderEvery   = fs.Duration("reminder-interval", 0, "how often to check reservations during a session")
+	)
+	fs.StringVar(outputFormat, "o", "", "shorthand for --output")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.applyFile(*configPath); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.applyEnv(); err != nil {
+		return Config{}, err
+	}
+
+	if *apiURL != "" {
+		if err := cfg.setURL(*apiURL); err != nil {
+			return Config{}, err
+		}
+	}
+	setIfNotEmpty(&cfg.API.Scheme, *scheme)
+	setIfNotEmpty(&cfg.API.Host, *host)
+	setIfNotEmpty(&cfg.API.Port, *port)
+	setIfNotEmpty(&cfg.API.Prefix, *prefix)
+	setIfNotEmpty(&cfg.Transport.CAFile, *caFile)
+	if *insecure {
+		cfg.Transport.InsecureSkipVerify = true
+	}
+	setIfPositive(&cfg.Transport.RequestTimeout, *requestTimeout)
+	setIfPositive(&cfg.AccessTokenTTL, *accessTokenTTL)
+	setIfPositive(&cfg.RefreshTokenTTL, *refreshTokenTTL)
+	setIfPositive(&cfg.RefreshMargin, *refreshMargin)
+	setIfPositive(&cfg.RefreshWarning, *refreshWarning)
+	cfg.SkipHealthCheck = *skipHealthCheck
+	if *retries > 0 {
+		cfg.Retry.MaxAttempts = *retries
+	}
+	setIfPositive(&cfg.Retry.BaseDelay, *retryBaseDelay)
+	setIfPositive(&cfg.Retry.MaxDelay, *retryMaxDelay)
+	if *idempotencyKeys {
+		cfg.Retry.IdempotencyKeys = true
+	}
+	if *debug {
+		cfg.Debug = true
+	}
+	if *sessionStore {
+		cfg.SessionStore = true
+	}
+	setIfNotEmpty(&cfg.SessionFile, *sessionFile)
+	if cfg.SessionStore && cfg.SessionFile == "" {
+		dir, err := Dir()
+		if err != nil {
+			return Config{}, fmt.Errorf("locating session file: %w", err)
+		}
+		cfg.SessionFile = filepath.Join(dir, "session.json")
+	}
+	setIfNotEmpty(&cfg.Profile, *profileName)
+	if *outputFormat != "" {
+		if err := cfg.setOutput(*outputFormat); err != nil {
+			return Config{}, err
+		}
+	}
+	if *pageSize != 0 {
+		if err := cfg.setPageSize(*pageSize); err != nil {
+			return Config{}, err
+		}
+	}
+	if *dueSoonDays != 0 {
+		if err := cfg.setDueSoonDays(*dueSoonDays); err != nil {
+			return Config{}, err
+		}
+	}
+	setIfPositive(&cfg.ReminderInterval, *reminderEvery)
+	if dir, err := Dir(); err == nil {
+		cfg.ProfilesFile = filepath.Join(dir, "profiles.json")
+		cfg.FavoritesFile = filepath.Join(dir, "favorites.json")
+	}
+
+	cfg.Args = fs.Args()
+
+	cfg.API.Prefix = strings.TrimRight(cfg.API.Prefix, "/")
+	if err := cfg.API.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) applyFile(path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv(envConfig)
+		explicit = path != ""
+	}
+	if !explicit {
+		dir, err := Dir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(dir, "config.json")
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	var fc fileConfig
+	if err = json.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	if fc.URL != "" {
+		if err = c.setURL(fc.URL); err != nil {
+			return err
+		}
+	}
+	setIfNotEmpty(&c.API.Scheme, fc.API.Scheme)
+	setIfNotEmpty(&c.API.Host, fc.API.Host)
+	setIfNotEmpty(&c.API.Port, fc.API.Port)
+	setIfNotEmpty(&c.API.Prefix, fc.API.Prefix)
+	setIfNotEmpty(&c.Transport.CAFile, fc.CAFile)
+	if fc.Insecure {
+		c.Transport.InsecureSkipVerify = true
+	}
+	if fc.Retry.MaxAttempts > 0 {
+		c.Retry.MaxAttempts = fc.Retry.MaxAttempts
+	}
+	if fc.Retry.IdempotencyKeys {
+		c.Retry.IdempotencyKeys = true
+	}
+	if fc.Debug {
+		c.Debug = true
+	}
+	if fc.SessionStore {
+		c.SessionStore = true
+	}
+	setIfNotEmpty(&c.SessionFile, fc.SessionFile)
+	setIfNotEmpty(&c.Profile, fc.Profile)
+	if fc.Output != "" {
+		if err = c.setOutput(fc.Output); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+	if fc.PageSize != 0 {
+		if err = c.setPageSize(fc.PageSize); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	if fc.DueSoonDays != 0 {
+		if err = c.setDueSoonDays(fc.DueSoonDays); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	for _, d := range []struct {
+		dst *time.Duration
+		val string
+	}{
+		{&c.Transport.RequestTimeout, fc.RequestTimeout},
+		{&c.AccessTokenTTL, fc.AccessTokenTTL},
+		{&c.RefreshTokenTTL, fc.RefreshTokenTTL},
+		{&c.RefreshMargin, fc.RefreshMargin},
+		{&c.RefreshWarning, fc.RefreshWarning},
+		{&c.Retry.BaseDelay, fc.Retry.BaseDelay},
+		{&c.Retry.MaxDelay, fc.Retry.MaxDelay},
+		{&c.ReminderInterval, fc.ReminderInterval},
+	} {
+		if err = setDuration(d.dst, d.val); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) applyEnv() error {
+	if u := os.Getenv(envAPIURL); u != "" {
+		if err := c.setURL(u); err != nil {
+			return fmt.Errorf("%s: %w", envAPIURL, err)
+		}
+	}
+	setIfNotEmpty(&c.API.Scheme, os.Getenv(envAPIScheme))
+	setIfNotEmpty(&c.API.Host, os.Getenv(envAPIHost))
+	setIfNotEmpty(&c.API.Port, os.Getenv(envAPIPort))
+	setIfNotEmpty(&c.API.Prefix, os.Getenv(envAPIPrefix))
+	setIfNotEmpty(&c.Transport.CAFile, os.Getenv(envCAFile))
+
+	if v := os.Getenv(envRetries); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil || retries < 1 {
+			return fmt.Errorf("%s: invalid number of attempts %q", envRetries, v)
+		}
+		c.Retry.MaxAttempts = retries
+	}
+	if v := os.Getenv(envDebug); v != "" {
+		debug, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envDebug, err)
+		}
+		c.Debug = debug
+	}
+	if v := os.Getenv(envSessionStore); v != "" {
+		store, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", envSessionStore, err)
+		}
+		c.SessionStore = store
+	}
+	setIfNotEmpty(&c.SessionFile, os.Getenv(envSessionFile))
+	c.SessionPassphrase = os.Getenv(envSessionPassphrase)
+	setIfNotEmpty(&c.Profile, os.Getenv(envProfile))
+	if v := os.Getenv(envOutput); v != "" {
+		if err := c.setOutput(v); err != nil {
+			return fmt.Errorf("%s: %w", envOutput, err)
+		}
+	}
+	if v := os.Getenv(envPageSize); v != "" {
+		size, err := strconv.ParseUint(v, 10, 0)
+		if err != nil {
+			return fmt.Errorf("%s: invalid page size %q", envPageSize, v)
+		}
+		if err = c.setPageSize(uint(size)); err != nil {
+			return fmt.Errorf("%s: %w", envPageSize, err)
+		}
+	}
+	if v := os.Getenv(envDueSoonDays); v != "" {
+		days, err := strconv.ParseUint(v, 10, 0)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number of days %q", envDueSoonDays, v)
+		}
+		if err = c.setDueSoonDays(uint(days)); err != nil {
+			return fmt.Errorf("%s: %w", envDueSoonDays, err)
+		}
+	}
+	c.Phone = os.Getenv(envPhone)
+	c.Password = os.Getenv(envPassword)
+
+	for _, d := range []struct {
+		dst *time.Duration
+		env string
+	}{
+		{&c.Transport.RequestTimeout, envRequestTimeout},
+		{&c.AccessTokenTTL, envAccessTokenTTL},
+		{&c.RefreshTokenTTL, envRefreshTokenTTL},
+	} {
+		if err := setDuration(d.dst, os.Getenv(d.env)); err != nil {
+			return fmt.Errorf("%s: %w", d.env, err)
+		}
+	}
+
+	return nil
+}
+
+// setURL разбирает полный адрес API на части
+func (c *Config) setURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid API url %q: %w", raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid API url %q: scheme and host are required", raw)
+	}
+
+	c.API = APIConfig{
+		Scheme: u.Scheme,
+		Host:   u.Hostname(),
+		Port:   u.Port(),
+		Prefix: strings.TrimRight(u.Path, "/"),
+	}
+
+	return nil
+}
+
+func (c *Config) setOutput(name string) error {
+	format, err := output.ParseFormat(name)
+	if err != nil {
+		return err
+	}
+	c.Output = format
+
+	return nil
+}
+
+// MaxPageSize - наибольший размер страницы каталога
+const MaxPageSize = 100
+
+func (c *Config) setPageSize(size uint) error {
+	if size < 1 || size > MaxPageSize {
+		return fmt.Errorf("page size must be from 1 to %d, got %d", MaxPageSize, size)
+	}
+	c.PageSize = size
+
+	return nil
+}
+
+// MaxDueSoonDays - наибольший срок напоминания о возврате
+const MaxDueSoonDays = 30
+
+func (c *Config) setDueSoonDays(days uint) error {
+	if days < 1 || days > MaxDueSoonDays {
+		return fmt.Errorf("due soon days must be from 1 to %d, got %d", MaxDueSoonDays, days)
+	}
+	c.DueSoonDays = days
+
+	return nil
+}
+
+// Dir возвращает каталог настроек BookSmart в пользовательском каталоге конфигурации
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "booksmart"), nil
+}
+
+func setIfNotEmpty(dst *string, val string) {
+	if val != "" {
+		*dst = val
+	}
+}
+
+func setIfPositive(dst *time.Duration, val time.Duration) {
+	if val > 0 {
+		*dst = val
+	}
+}
+
+func setDuration(dst *time.Duration, val string) error {
+	if val == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		return err
+	}
+	setIfPositive(dst, d)
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	const file = `{
+		"api": {"host": "file.example.com", "port": "9000"},
+		"output": "json",
+		"page_size": 20,
+		"access_token_ttl": "5m"
+	}`
+
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+
+		wantHost     string
+		wantPort     string
+		wantOutput   output.Format
+		wantPageSize uint
+		wantTTL      time.Duration
+	}{
+		{
+			name:         "defaults",
+			wantHost:     "localhost",
+			wantPort:     "8000",
+			wantOutput:   output.Table,
+			wantPageSize: 10,
+			wantTTL:      15 * time.Minute,
+		},
+		{
+			name:         "file overrides defaults",
+			file:         file,
+			wantHost:     "file.example.com",
+			wantPort:     "9000",
+			wantOutput:   output.JSON,
+			wantPageSize: 20,
+			wantTTL:      5 * time.Minute,
+		},
+		{
+			name: "env overrides file",
+			file: file,
+			env: map[string]string{
+				envAPIPort:        "9100",
+				envOutput:         "yaml",
+				envAccessTokenTTL: "7m",
+			},
+			wantHost:     "file.example.com",
+			wantPort:     "9100",
+			wantOutput:   output.YAML,
+			wantPageSize: 20,
+			wantTTL:      7 * time.Minute,
+		},
+		{
+			name: "flags override env",
+			file: file,
+			env: map[string]string{
+				envAPIPort:        "9100",
+				envOutput:         "yaml",
+				envAccessTokenTTL: "7m",
+			},
+			args:         []string{"--api-port", "9200", "-o", "csv", "--access-token-ttl", "9m", "--page-size", "30"},
+			wantHost:     "file.example.com",
+			wantPort:     "9200",
+			wantOutput:   output.CSV,
+			wantPageSize: 30,
+			wantTTL:      9 * time.Minute,
+		},
+		{
+			name:         "env without file overrides defaults",
+			env:          map[string]string{envAPIURL: "https://env.example.com:8443/booksmart"},
+			wantHost:     "env.example.com",
+			wantPort:     "8443",
+			wantOutput:   output.Table,
+			wantPageSize: 10,
+			wantTTL:      15 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateEnv(t)
+
+			if tt.file != "" {
+				path := filepath.Join(t.TempDir(), "config.json")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv(envConfig, path)
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load(tt.args)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			if cfg.API.Host != tt.wantHost || cfg.API.Port != tt.wantPort {
+				t.Errorf("API = %s:%s, want %s:%s", cfg.API.Host, cfg.API.Port, tt.wantHost, tt.wantPort)
+			}
+			if cfg.Output != tt.wantOutput {
+				t.Errorf("Output = %q, want %q", cfg.Output, tt.wantOutput)
+			}
+			if cfg.PageSize != tt.wantPageSize {
+				t.Errorf("PageSize = %d, want %d", cfg.PageSize, tt.wantPageSize)
+			}
+			if cfg.AccessTokenTTL != tt.wantTTL {
+				t.Errorf("AccessTokenTTL = %v, want %v", cfg.AccessTokenTTL, tt.wantTTL)
+			}
+		})
+	}
+}
+
+// isolateEnv убирает настройки BOOKSMART_* из окружения теста и подменяет
+// каталог настроек, чтобы не прочитать config.json пользователя
+func isolateEnv(t *testing.T) {
+	t.Helper()
+
+	for _, kv := range os.Environ() {
+		if key, _, _ := strings.Cut(kv, "="); strings.HasPrefix(key, "BOOKSMART_") {
+			t.Setenv(key, "")
+		}
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+}
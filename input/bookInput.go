@@ -1,289 +1,184 @@
 package input
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"os"
-	"strconv"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
 	"strings"
 )
 
-func IsWithParams() (bool, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Would you like to enter search parameters?(Y/N): ")
-
-	isWithParams, err := reader.ReadString('\n')
+func (in *Input) IsWithParams() (bool, error) {
+	isWithParams, err := in.Line("Would you like to enter search parameters?(Y/N): ")
 	if err != nil {
 		return false, err
 	}
 
-	isWithParams = strings.TrimSpace(isWithParams)
-	if isWithParams != "n" && isWithParams != "N" {
-		return true, nil
-	}
-
-	return false, nil
+	return !strings.EqualFold(isWithParams, "n"), nil
 }
 
-func Title() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input title: ")
-
-	title, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	title = strings.TrimSpace(title)
-
-	return title, nil
+func (in *Input) Title() (string, error) {
+	return in.ask(in.Line, "Input title: ", notEmpty)
 }
 
-func Author() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input author: ")
-
-	author, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	author = strings.TrimSpace(author)
-
-	return author, nil
+func (in *Input) Author() (string, error) {
+	return in.ask(in.Line, "Input author: ", notEmpty)
 }
 
-func Publisher() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input publisher: ")
-
-	publisher, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	publisher = strings.TrimSpace(publisher)
-
-	return publisher, nil
+func (in *Input) Publisher() (string, error) {
+	return in.Line("Input publisher: ")
 }
 
-func Rarity() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input rarity: ")
-
-	rarity, err := reader.ReadString('\n')
+func (in *Input) Rarity() (string, error) {
+	value, err := in.ask(in.Line, "Input rarity (Common/Rare/Unique): ", rarity)
 	if err != nil {
 		return "", err
 	}
 
-	rarity = strings.TrimSpace(rarity)
-
-	return rarity, nil
+	return normalizeRarity(value), nil
 }
 
-func Genre() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input genre: ")
-
-	genre, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	genre = strings.TrimSpace(genre)
-
-	return genre, nil
+func (in *Input) Genre() (string, error) {
+	return in.Line("Input genre: ")
 }
 
-func PublishingYear() (uint, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input publishing year: ")
-
-	yearStr, err := reader.ReadString('\n')
-	if err != nil {
-		return 0, err
-	}
-
-	yearStr = strings.TrimSpace(yearStr)
-
-	yearInt, err := strconv.Atoi(yearStr)
-	if err != nil {
-		return 0, err
-	}
-
-	year := uint(yearInt)
-
-	return year, nil
+func (in *Input) PublishingYear() (uint, error) {
+	return in.askUint("Input publishing year: ", 1, currentYear())
 }
 
-func Language() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input language: ")
-
-	language, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	language = strings.TrimSpace(language)
-
-	return language, nil
+func (in *Input) Language() (string, error) {
+	return in.Line("Input language: ")
 }
 
-func AgeLimit() (uint, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input age limit: ")
-
-	ageLimitStr, err := reader.ReadString('\n')
-	if err != nil {
-		return 0, err
-	}
-
-	ageLimitStr = strings.TrimSpace(ageLimitStr)
-
-	ageLimitInt, err := strconv.Atoi(ageLimitStr)
-	if err != nil {
-		return 0, err
-	}
-
-	ageLimit := uint(ageLimitInt)
-
-	return ageLimit, nil
+func (in *Input) AgeLimit() (uint, error) {
+	return in.askUint("Input age limit: ", 0, maxAgeLimit)
 }
 
-func CopiesNumber() (uint, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input book's copies number: ")
-
-	copiesNumStr, err := reader.ReadString('\n')
-	if err != nil {
-		return 0, err
-	}
-
-	copiesNumStr = strings.TrimSpace(copiesNumStr)
-
-	copiesNumInt, err := strconv.Atoi(copiesNumStr)
-	if err != nil {
-		return 0, err
-	}
-
-	copiesNum := uint(copiesNumInt)
-
-	return copiesNum, nil
+func (in *Input) CopiesNumber() (uint, error) {
+	return in.askUint("Input book's copies number: ", 1, maxCopies)
 }
 
-func Params() (dto.BookParamsDTO, error) {
-	var params dto.BookParamsDTO
-	var err error
+// paramPrompts - порядок пошагового ввода фильтров поиска
+var paramPrompts = []struct {
+	key    string
+	prompt string
+}{
+	{"title", "Input title: "},
+	{"author", "Input author: "},
+	{"publisher", "Input publisher: "},
+	{"rarity", "Input rarity (Common/Rare/Unique): "},
+	{"genre", "Input genre (several separated by commas): "},
+	{"year", "Input publishing year or range (e.g. 1850-1900): "},
+	{"language", "Input language (several separated by commas): "},
+	{"age", "Input age limit or range (e.g. -12): "},
+}
 
-	params.Title, err = Title()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
-	}
-	params.Author, err = Author()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
-	}
-	params.Publisher, err = Publisher()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
-	}
-	params.Rarity, err = Rarity()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
-	}
-	params.Genre, err = Genre()
+// Params запрашивает параметры поиска: одной строкой фильтров или по одному,
+// пустой ввод оставляет параметр незаданным
+func (in *Input) Params() (search.Query, error) {
+	line, err := in.ask(in.Line, "Input filters (e.g. author:Tolstoy genre:novel year:1869)\n"+
+		"or press Enter to fill them in one by one: ", optional(validParams))
 	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
+		return search.Query{}, err
 	}
-	params.PublishingYear, err = PublishingYear()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
-	}
-	params.Language, err = Language()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
+	if line != "" {
+		return ParseParams(line)
 	}
-	params.AgeLimit, err = AgeLimit()
-	if err != nil {
-		return dto.BookParamsDTO{Limit: 5, Offset: 0}, err
-	}
-
-	return params, nil
-}
 
-func BookPagesNumber() (int, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input book pages number: ")
-
-	numStr, err := reader.ReadString('\n')
-
-	if err != nil {
-		return 0, err
+	var query search.Query
+	for _, p := range paramPrompts {
+		// проверка разбирает значение в черновик, запрос меняется только
+		// после того, как значение принято
+		validParam := func(value string) error { return setFilter(&search.Query{}, p.key, value) }
+		var value string
+		if value, err = in.ask(in.Line, p.prompt, optional(validParam)); err != nil {
+			return search.Query{}, err
+		}
+		if value == "" {
+			continue
+		}
+		if err = setFilter(&query, p.key, value); err != nil {
+			return search.Query{}, err
+		}
 	}
 
-	numStr = strings.TrimSpace(numStr)
-
-	numInt, err := strconv.Atoi(numStr)
-	if err != nil {
-		return 0, err
-	}
+	return query, nil
+}
 
-	return numInt, nil
+// BookRef запрашивает книгу: номер строки, начало ID или полный ID
+func (in *Input) BookRef() (string, error) {
+	return in.ask(in.Line, "Input book No. or ID: ", notEmpty)
 }
 
-func Book() (dto.BookDTO, error) {
+func (in *Input) Book() (dto.BookDTO, error) {
 	var book dto.BookDTO
 	var err error
 
-	book.Title, err = Title()
+	book.Title, err = in.Title()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.Author, err = Author()
+	book.Author, err = in.Author()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.Publisher, err = Publisher()
+	book.Publisher, err = in.Publisher()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.CopiesNumber, err = CopiesNumber()
+	book.CopiesNumber, err = in.CopiesNumber()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.Rarity, err = Rarity()
+	book.Rarity, err = in.Rarity()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.Genre, err = Genre()
+	book.Genre, err = in.Genre()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.PublishingYear, err = PublishingYear()
+	book.PublishingYear, err = in.PublishingYear()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.Language, err = Language()
+	book.Language, err = in.Language()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
-	book.AgeLimit, err = AgeLimit()
+	book.AgeLimit, err = in.AgeLimit()
 	if err != nil {
 		return dto.BookDTO{}, err
 	}
 
 	return book, nil
 }
+
+func validParams(line string) error {
+	_, err := ParseParams(line)
+	return err
+}
+
+// PageNumber запрашивает номер страницы каталога (с единицы)
+func (in *Input) PageNumber() (uint, error) {
+	return in.askUint("Input page number: ", 1, maxPageNumber)
+}
+
+// PageSize запрашивает число книг на странице
+func (in *Input) PageSize(max uint) (uint, error) {
+	return in.askUint(fmt.Sprintf("Input page size (1-%d): ", max), 1, max)
+}
+
+// BookRefs запрашивает несколько книг через запятую
+func (in *Input) BookRefs() (string, error) {
+	return in.ask(in.Line, "Input book No., range or ID (several separated by commas, e.g. 1,3,5-8): ", notEmpty)
+}
+
+// Position запрашивает новое место книги в списке
+func (in *Input) Position() (uint, error) {
+	return in.askUint("Input new position (No.): ", 0, maxPageNumber)
+}
+
+// ExportPath запрашивает файл для экспорта
+func (in *Input) ExportPath() (string, error) {
+	return in.ask(in.Line, "Input file name (.json, .ndjson, .csv, .yaml or .md): ", notEmpty)
+}
@@ -0,0 +1,201 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	"strconv"
+	"strings"
+)
+
+// Строка фильтров поиска - пары ключ:значение через пробел, значения с
+// пробелами берутся в кавычки. Жанры и языки можно перечислить через запятую,
+// год и возрастное ограничение - задать диапазоном:
+//
+//	author:Tolstoy genre:novel,poetry year:1850-1900 age:-12 title:"War and Peace"
+const FiltersHelp = `title, author, publisher, rarity, genre, language, year, age, copies`
+
+// ParseParams разбирает строку фильтров в запрос поиска
+func ParseParams(line string) (search.Query, error) {
+	var query search.Query
+
+	filters, err := SplitArgs(line)
+	if err != nil {
+		return search.Query{}, err
+	}
+
+	if err = ParseFilters(&query, filters); err != nil {
+		return search.Query{}, err
+	}
+
+	return query, nil
+}
+
+// ParseFilters дополняет запрос фильтрами вида ключ:значение
+func ParseFilters(query *search.Query, filters []string) error {
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, ":")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key:value, got %q", filter)
+		}
+		if value == "" {
+			return fmt.Errorf("empty value for %q", key)
+		}
+
+		if err := setFilter(query, strings.ToLower(key), value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setFilter задает фильтр key. Одно значение или вырожденный диапазон
+// отправляется на сервер, остальное проверяется на клиенте
+func setFilter(query *search.Query, key, value string) error {
+	params := &query.Params
+
+	switch key {
+	case "title":
+		params.Title = value
+	case "author":
+		params.Author = value
+	case "publisher":
+		params.Publisher = value
+	case "genre":
+		params.Genre, query.Genres = splitValues(value)
+	case "language", "lang":
+		params.Language, query.Languages = splitValues(value)
+	case "rarity":
+		if err := rarity(value); err != nil {
+			return err
+		}
+		params.Rarity = normalizeRarity(value)
+	case "year":
+		return setRange(value, 1, currentYear(), &params.PublishingYear, &query.Year)
+	case "age", "age_limit":
+		return setRange(value, 0, maxAgeLimit, &params.AgeLimit, &query.AgeLimit)
+	case "copies":
+		copies, err := parseUintInRange(value, 1, maxCopies)
+		if err != nil {
+			return err
+		}
+		params.CopiesNumber = copies
+	default:
+		return fmt.Errorf("unknown filter, expected one of: %s", FiltersHelp)
+	}
+
+	return nil
+}
+
+// splitValues разбирает список через запятую: одно значение возвращается
+// отдельно, несколько - списком
+func splitValues(value string) (string, []string) {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	if len(values) == 1 {
+		return values[0], nil
+	}
+
+	return "", values
+}
+
+// setRange разбирает число или диапазон "from-to" (любая граница может быть
+// опущена). Ноль сервер считает отсутствием фильтра, поэтому точное значение 0
+// проверяется на клиенте
+func setRange(value string, min, max uint, exact *uint, rng *search.Range) error {
+	fromStr, toStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		toStr = fromStr
+	}
+
+	var res search.Range
+	for _, bound := range []struct {
+		str string
+		dst **uint
+	}{{fromStr, &res.From}, {toStr, &res.To}} {
+		if bound.str == "" {
+			continue
+		}
+		num, err := parseUintInRange(strings.TrimSpace(bound.str), min, max)
+		if err != nil {
+			return err
+		}
+		*bound.dst = &num
+	}
+
+	switch {
+	case !res.IsSet():
+		return errors.New("empty range")
+	case res.From != nil && res.To != nil && *res.From > *res.To:
+		return errors.New("range start is greater than its end")
+	case res.From != nil && res.To != nil && *res.From == *res.To && *res.From != 0:
+		*exact, *rng = *res.From, search.Range{}
+	default:
+		*exact, *rng = 0, res
+	}
+
+	return nil
+}
+
+func parseUintInRange(value string, min, max uint) (uint, error) {
+	if err := uintInRange(min, max)(value); err != nil {
+		return 0, err
+	}
+
+	num, _ := strconv.ParseUint(value, 10, 0)
+
+	return uint(num), nil
+}
+
+// SplitArgs разбивает строку на аргументы с учетом кавычек и \
+func SplitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, c := range line {
+		switch {
+		case escaped:
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote, inArg = c, true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,104 @@
+package input
+
+import (
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	"reflect"
+	"testing"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    search.Query
+		wantErr bool
+	}{
+		{
+			name: "single values go to the server",
+			line: `author:Tolstoy genre:novel title:"War and Peace"`,
+			want: func() search.Query {
+				var q search.Query
+				q.Params.Author, q.Params.Genre, q.Params.Title = "Tolstoy", "novel", "War and Peace"
+				return q
+			}(),
+		},
+		{
+			name: "several genres and languages are checked on the client",
+			line: "genre:novel,,poetry, lang:en,ru",
+			want: search.Query{Genres: []string{"novel", "poetry"}, Languages: []string{"en", "ru"}},
+		},
+		{
+			name: "one value after commas goes to the server",
+			line: "genre:,novel,",
+			want: func() search.Query {
+				var q search.Query
+				q.Params.Genre = "novel"
+				return q
+			}(),
+		},
+		{
+			name: "closed range",
+			line: "year:1850-1900",
+			want: search.Query{Year: search.Range{From: uintPtr(1850), To: uintPtr(1900)}},
+		},
+		{
+			name: "open ranges",
+			line: "year:1850- age:-12",
+			want: search.Query{Year: search.Range{From: uintPtr(1850)}, AgeLimit: search.Range{To: uintPtr(12)}},
+		},
+		{
+			name: "degenerate range is an exact value",
+			line: "year:1869-1869 age:16",
+			want: func() search.Query {
+				var q search.Query
+				q.Params.PublishingYear, q.Params.AgeLimit = 1869, 16
+				return q
+			}(),
+		},
+		{
+			name: "age 0 is checked on the client",
+			line: "age:0",
+			want: search.Query{AgeLimit: search.Range{From: uintPtr(0), To: uintPtr(0)}},
+		},
+		{
+			name: "rarity is normalized",
+			line: "rarity:rare",
+			want: func() search.Query {
+				var q search.Query
+				q.Params.Rarity = "Rare"
+				return q
+			}(),
+		},
+		{name: "reversed range", line: "year:1900-1850", wantErr: true},
+		{name: "empty range", line: "year:-", wantErr: true},
+		{name: "age above limit", line: "age:-30", wantErr: true},
+		{name: "not a number", line: "year:old", wantErr: true},
+		{name: "unknown key", line: "color:red", wantErr: true},
+		{name: "missing value", line: "author:", wantErr: true},
+		{name: "unknown rarity", line: "rarity:legendary", wantErr: true},
+		{name: "value after a space needs quotes", line: "lang:en, ru", wantErr: true},
+		{name: "unterminated quote", line: `title:"War`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseParams(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseParams(%q) = %+v, want error", tt.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseParams(%q): %v", tt.line, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseParams(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
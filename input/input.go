@@ -0,0 +1,195 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrBack - пользователь нажал Ctrl+D (или ввод закончился): вернуться назад
+var ErrBack = errors.New("back")
+
+// Input - единый источник ввода для всех запросов к пользователю. Поверх
+// терминала работает построчный редактор с историей (стрелка вверх), поверх
+// любого другого io.Reader - общий буфер, поэтому вставленные или переданные
+// через pipe строки не теряются между запросами
+type Input struct {
+	reader *bufio.Reader
+	out    io.Writer
+
+	// term и fd заданы, только если ввод идет с терминала
+	term *term.Terminal
+	fd   int
+
+	// interrupts - сигналы Ctrl+C; пока они передаются сюда, Ctrl+C в запросе
+	// работает как Ctrl+D. pending - строка, чтение которой прервал сигнал:
+	// ее получит следующий запрос
+	interrupts <-chan os.Signal
+	pending    chan readResult
+}
+
+type readResult struct {
+	line string
+	err  error
+}
+
+// errInterrupted - чтение прервано по Ctrl+C
+var errInterrupted = errors.New("interrupted")
+
+// New создает ввод из произвольного источника, например заготовленного текста
+func New(r io.Reader, out io.Writer) *Input {
+	return &Input{
+		reader: bufio.NewReader(r),
+		out:    out,
+	}
+}
+
+// NewStdin создает ввод из stdin; если stdin и stdout - терминал, включается
+// редактирование строки и история
+func NewStdin() *Input {
+	in := New(os.Stdin, os.Stdout)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) && term.IsTerminal(int(os.Stdout.Fd())) {
+		in.fd = fd
+		in.term = term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, "")
+	}
+
+	return in
+}
+
+// HandleInterrupts передает вводу сигналы Ctrl+C: запрос, во время которого
+// пришел сигнал, возвращает ErrBack. nil - сигналы больше не обрабатываются
+func (in *Input) HandleInterrupts(interrupts <-chan os.Signal) {
+	in.interrupts = interrupts
+}
+
+// Line выводит приглашение и читает строку без пробелов по краям
+func (in *Input) Line(prompt string) (string, error) {
+	if in.term != nil {
+		return in.termLine(prompt, false)
+	}
+
+	_, _ = fmt.Fprint(in.out, prompt)
+
+	line, err := in.readLine()
+	if errors.Is(err, errInterrupted) || errors.Is(err, io.EOF) && line == "" {
+		_, _ = fmt.Fprintln(in.out)
+		return "", ErrBack
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// readLine читает строку из буфера. Если сигналы Ctrl+C обрабатываются,
+// чтение идет в отдельной горутине, чтобы сигнал мог прервать ожидание
+func (in *Input) readLine() (string, error) {
+	if in.interrupts == nil && in.pending == nil {
+		return in.reader.ReadString('\n')
+	}
+
+	// сигнал, пришедший до запроса, относится к предыдущей операции
+	select {
+	case <-in.interrupts:
+	default:
+	}
+
+	if in.pending == nil {
+		in.pending = make(chan readResult, 1)
+		go func(pending chan<- readResult) {
+			line, err := in.reader.ReadString('\n')
+			pending <- readResult{line: line, err: err}
+		}(in.pending)
+	}
+
+	select {
+	case res := <-in.pending:
+		in.pending = nil
+		return res.line, res.err
+	case <-in.interrupts:
+		return "", errInterrupted
+	}
+}
+
+// Secret читает строку, не отображая ее (пароль, парольная фраза)
+func (in *Input) Secret(prompt string) (string, error) {
+	if in.term != nil {
+		return in.termLine(prompt, true)
+	}
+
+	return in.Line(prompt)
+}
+
+// termLine читает строку редактором терминала. Терминал переводится в raw-режим
+// только на время чтения, чтобы остальной вывод программы шел как обычно
+func (in *Input) termLine(prompt string, secret bool) (string, error) {
+	state, err := term.MakeRaw(in.fd)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = term.Restore(in.fd, state) }()
+
+	var line string
+	if secret {
+		line, err = in.term.ReadPassword(prompt)
+	} else {
+		in.term.SetPrompt(prompt)
+		line, err = in.term.ReadLine()
+	}
+
+	// Ctrl+D на пустой строке и Ctrl+C редактор возвращает как io.EOF
+	if errors.Is(err, io.EOF) {
+		_, _ = in.term.Write([]byte("\n"))
+		return "", ErrBack
+	}
+	// вставка из буфера обмена: строка прочитана, остальное ждет следующих запросов
+	if errors.Is(err, term.ErrPasteIndicator) {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// Int читает целое число
+func (in *Input) Int(prompt string) (int, error) {
+	str, err := in.Line(prompt)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(str)
+}
+
+// Confirm задает вопрос "да/нет"; ответ по умолчанию - нет
+func (in *Input) Confirm(question string) (bool, error) {
+	answer, err := in.Line(question + " (y/N): ")
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"), nil
+}
+
+// MenuItem читает пункт меню; Ctrl+D выбирает пункт 0 - "назад" в любом меню
+func (in *Input) MenuItem() (int, error) {
+	menuItem, err := in.Int("Input menu item: ")
+	if errors.Is(err, ErrBack) {
+		return 0, nil
+	}
+
+	return menuItem, err
+}
@@ -0,0 +1,57 @@
+package input
+
+import (
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/profile"
+	"strings"
+)
+
+func (in *Input) ProfileName() (string, error) {
+	return in.ask(in.Line, "Input profile name: ", profile.ValidateName)
+}
+
+func (in *Input) APIURL() (string, error) {
+	return in.Line("Input API url (empty for default): ")
+}
+
+func (in *Input) Role() (string, error) {
+	role, err := in.Line("Input role (reader/admin): ")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(role), nil
+}
+
+func (in *Input) PasswordEnv() (string, error) {
+	return in.Line("Input environment variable with password (empty to ask on sign in): ")
+}
+
+func (in *Input) ProfileParams() (profile.Profile, error) {
+	var (
+		res profile.Profile
+		err error
+	)
+
+	res.Name, err = in.ProfileName()
+	if err != nil {
+		return profile.Profile{}, err
+	}
+	res.APIURL, err = in.APIURL()
+	if err != nil {
+		return profile.Profile{}, err
+	}
+	res.Role, err = in.Role()
+	if err != nil {
+		return profile.Profile{}, err
+	}
+	res.PhoneNumber, err = in.PhoneNumber()
+	if err != nil {
+		return profile.Profile{}, err
+	}
+	res.PasswordEnv, err = in.PasswordEnv()
+	if err != nil {
+		return profile.Profile{}, err
+	}
+
+	return res, nil
+}
@@ -1,59 +1,30 @@
 package input
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"os"
-	"strconv"
-	"strings"
 )
 
-func Review() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input review: ")
-
-	review, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	review = strings.TrimSpace(review)
-
-	return review, nil
+func (in *Input) Review() (string, error) {
+	return in.Line("Input review: ")
 }
 
-func Rating() (int, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input rating: ")
-
-	ratingStr, err := reader.ReadString('\n')
-	if err != nil {
-		return 0, err
-	}
-
-	ratingStr = strings.TrimSpace(ratingStr)
-
-	ratingInt, err := strconv.Atoi(ratingStr)
-	if err != nil {
-		return 0, err
-	}
+func (in *Input) Rating() (int, error) {
+	rating, err := in.askUint(fmt.Sprintf("Input rating (%d-%d): ", minRating, maxRating), minRating, maxRating)
 
-	return ratingInt, nil
+	return int(rating), err
 }
 
-func RatingParams() (dto.RatingInputDTO, error) {
+func (in *Input) RatingParams() (dto.RatingInputDTO, error) {
 	var (
 		ratingDTO dto.RatingInputDTO
 		err       error
 	)
 
-	if ratingDTO.Review, err = Review(); err != nil {
+	if ratingDTO.Review, err = in.Review(); err != nil {
 		return dto.RatingInputDTO{}, err
 	}
-	if ratingDTO.Rating, err = Rating(); err != nil {
+	if ratingDTO.Rating, err = in.Rating(); err != nil {
 		return dto.RatingInputDTO{}, err
 	}
 
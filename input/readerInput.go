@@ -1,100 +1,54 @@
 package input
 
 import (
-	"bufio"
-	"fmt"
-	"github.com/howeyc/gopass"
 	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"os"
-	"strconv"
-	"strings"
 )
 
-func Fio() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input your FIO: ")
-
-	fio, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	fio = strings.TrimSpace(fio)
-
-	return fio, nil
+func (in *Input) Fio() (string, error) {
+	return in.ask(in.Line, "Input your FIO: ", notEmpty)
 }
 
-func PhoneNumber() (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Printf("Input your phone number: ")
-
-	phoneNumber, err := reader.ReadString('\n')
-	if err != nil {
-		return "", err
-	}
-
-	phoneNumber = strings.TrimSpace(phoneNumber)
-
-	return phoneNumber, nil
+func (in *Input) PhoneNumber() (string, error) {
+	return in.ask(in.Line, "Input your phone number: ", phoneNumber)
 }
 
-func Age() (uint, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print("Input your age: ")
-
-	ageStr, err := reader.ReadString('\n')
-	if err != nil {
-		return 0, err
-	}
-
-	ageStr = strings.TrimSpace(ageStr)
-
-	ageInt, err := strconv.Atoi(ageStr)
-	if err != nil {
-		return 0, err
-	}
-
-	age := uint(ageInt)
-
-	return age, nil
+func (in *Input) Age() (uint, error) {
+	return in.askUint("Input your age: ", minAge, maxAge)
 }
 
-func Password() (string, error) {
-	fmt.Print("Input your password: ")
-
-	silentPassword, err := gopass.GetPasswdMasked()
-	if err != nil {
-		return "", err
-	}
+func (in *Input) Password() (string, error) {
+	return in.ask(in.Secret, "Input your password: ", notEmpty)
+}
 
-	password := string(silentPassword)
-	password = strings.TrimSpace(password)
+// NewPassword запрашивает пароль при регистрации и проверяет его надежность
+func (in *Input) NewPassword() (string, error) {
+	return in.ask(in.Secret, "Input your password: ", strongPassword)
+}
 
-	return password, nil
+// Passphrase запрашивает парольную фразу для шифрования сохраненной сессии
+func (in *Input) Passphrase() (string, error) {
+	return in.Secret("Input passphrase for saved session: ")
 }
 
-func SignUpParams() (dto.ReaderSignUpDTO, error) {
+func (in *Input) SignUpParams() (dto.ReaderSignUpDTO, error) {
 	var (
 		res dto.ReaderSignUpDTO
 		err error
 	)
 
-	res.Fio, err = Fio()
+	res.Fio, err = in.Fio()
 	if err != nil {
 		return dto.ReaderSignUpDTO{}, err
 	}
-	res.Age, err = Age()
+	res.Age, err = in.Age()
 	if err != nil {
 		return dto.ReaderSignUpDTO{}, err
 	}
-	res.PhoneNumber, err = PhoneNumber()
+	res.PhoneNumber, err = in.PhoneNumber()
 	if err != nil {
 		return dto.ReaderSignUpDTO{}, err
 	}
-	res.Password, err = Password()
+	res.Password, err = in.NewPassword()
 	if err != nil {
 		return dto.ReaderSignUpDTO{}, err
 	}
@@ -102,40 +56,20 @@ func SignUpParams() (dto.ReaderSignUpDTO, error) {
 	return res, nil
 }
 
-func SignInParams() (dto.ReaderSignInDTO, error) {
+func (in *Input) SignInParams() (dto.ReaderSignInDTO, error) {
 	var (
 		res dto.ReaderSignInDTO
 		err error
 	)
 
-	res.PhoneNumber, err = PhoneNumber()
+	res.PhoneNumber, err = in.PhoneNumber()
 	if err != nil {
 		return dto.ReaderSignInDTO{}, err
 	}
-	res.Password, err = Password()
+	res.Password, err = in.Password()
 	if err != nil {
 		return dto.ReaderSignInDTO{}, err
 	}
 
 	return res, nil
 }
-
-func MenuItem() (int, error) {
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print("Input menu item: ")
-
-	menuItemStr, err := reader.ReadString('\n')
-	if err != nil {
-		return 0, err
-	}
-
-	menuItemStr = strings.TrimSpace(menuItemStr)
-
-	menuItemInt, err := strconv.Atoi(menuItemStr)
-	if err != nil {
-		return 0, err
-	}
-
-	return menuItemInt, nil
-}
@@ -1,29 +1,177 @@
 package input
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
 	"strings"
+	"time"
 )
 
-func ReservationNumber() (int, error) {
-	reader := bufio.NewReader(os.Stdin)
+// Строка фильтров истории броней, например
+//
+//	state:issued,extended from:2024-09-01 to:2024-09-30 sort:-return
+//
+// book - начало ID или часть названия книги, reader - начало ID или номер
+// телефона читателя, overdue - yes или no
+const ReservationFiltersHelp = `state, from, to, book, reader, overdue, sort (return, issue; "-" for descending)`
 
-	fmt.Printf("Input reservation number: ")
+// reservationStates - состояния брони на сервере
+var reservationStates = []string{"Issued", "Extended", "Expired", "Closed"}
 
-	numStr, err := reader.ReadString('\n')
+// ReservationRef запрашивает бронь: номер строки, начало ID или полный ID
+func (in *Input) ReservationRef() (string, error) {
+	return in.ask(in.Line, "Input reservation number or ID: ", notEmpty)
+}
+
+// ReaderRef запрашивает читателя: номер телефона или бронь, по которой его найти
+func (in *Input) ReaderRef() (string, error) {
+	return in.ask(in.Line, "Input reader's phone number, or reservation number or ID: ", notEmpty)
+}
+
+// ReservationFilters запрашивает фильтры истории броней; Enter - без фильтров
+func (in *Input) ReservationFilters() (search.ReservationQuery, error) {
+	validFilters := func(line string) error {
+		_, err := ParseReservationFilters(line)
+		return err
+	}
+
+	line, err := in.ask(in.Line, "Input filters (e.g. state:issued from:2024-09-01 sort:return)\n"+
+		"or press Enter to show all: ", optional(validFilters))
+	if err != nil {
+		return search.ReservationQuery{}, err
+	}
+
+	return ParseReservationFilters(line)
+}
+
+// ParseReservationFilters разбирает строку фильтров истории броней
+func ParseReservationFilters(line string) (search.ReservationQuery, error) {
+	var query search.ReservationQuery
+
+	filters, err := SplitArgs(line)
 	if err != nil {
-		return 0, err
+		return search.ReservationQuery{}, err
+	}
+
+	if err = ParseReservationFilterArgs(&query, filters); err != nil {
+		return search.ReservationQuery{}, err
+	}
+
+	return query, nil
+}
+
+// ParseReservationFilterArgs дополняет запрос фильтрами вида ключ:значение
+func ParseReservationFilterArgs(query *search.ReservationQuery, filters []string) error {
+	for _, filter := range filters {
+		key, value, ok := strings.Cut(filter, ":")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key:value, got %q", filter)
+		}
+		if value == "" {
+			return fmt.Errorf("empty value for %q", key)
+		}
+
+		if err := setReservationFilter(query, strings.ToLower(key), value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return errors.New("from date is after to date")
+	}
+
+	return nil
+}
+
+func setReservationFilter(query *search.ReservationQuery, key, value string) error {
+	switch key {
+	case "state":
+		states, err := parseStates(value)
+		if err != nil {
+			return err
+		}
+		query.States = states
+	case "from":
+		date, err := parseDate(value)
+		if err != nil {
+			return err
+		}
+		query.From = &date
+	case "to":
+		date, err := parseDate(value)
+		if err != nil {
+			return err
+		}
+		query.To = &date
+	case "book":
+		query.Book = value
+	case "reader":
+		query.Reader = value
+	case "overdue":
+		overdue, err := parseYesNo(value)
+		if err != nil {
+			return err
+		}
+		query.Overdue = &overdue
+	case "sort":
+		query.Desc = strings.HasPrefix(value, "-")
+		switch field := strings.ToLower(strings.TrimPrefix(value, "-")); field {
+		case search.SortByReturnDate, search.SortByIssueDate:
+			query.SortBy = field
+		default:
+			return fmt.Errorf("unknown sort field %q, expected return or issue", field)
+		}
+	default:
+		return fmt.Errorf("unknown filter, expected one of: %s", ReservationFiltersHelp)
+	}
+
+	return nil
+}
+
+// parseStates разбирает список состояний через запятую без учета регистра
+func parseStates(value string) ([]string, error) {
+	var states []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+
+		i := -1
+		for j, state := range reservationStates {
+			if strings.EqualFold(v, state) {
+				i = j
+			}
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("unknown state %q, expected %s", v, strings.Join(reservationStates, ", "))
+		}
+		states = append(states, reservationStates[i])
 	}
 
-	numStr = strings.TrimSpace(numStr)
+	if len(states) == 0 {
+		return nil, errors.New("no states given")
+	}
 
-	numInt, err := strconv.Atoi(numStr)
+	return states, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
 	if err != nil {
-		return 0, err
+		return time.Time{}, fmt.Errorf("expected date as YYYY-MM-DD, got %q", value)
 	}
 
-	return numInt, nil
+	return date, nil
+}
+
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "y", "yes", "true":
+		return true, nil
+	case "n", "no", "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("expected yes or no, got %q", value)
+	}
 }
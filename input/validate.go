@@ -0,0 +1,151 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// validator проверяет введенное значение; текст ошибки показывается пользователю
+type validator func(value string) error
+
+const (
+	phoneNumberLen = 11
+	passwordLen    = 10
+	minAge         = 1
+	maxAge         = 120
+	maxAgeLimit    = 18
+	maxCopies      = 10000
+	minRating      = 1
+	maxRating      = 5
+	maxPageNumber  = 1 << 20
+)
+
+var rarities = []string{"Common", "Rare", "Unique"}
+
+// ask повторяет запрос, пока значение не пройдет все проверки. Уже введенные
+// поля формы при этом не теряются, Ctrl+D по-прежнему отменяет всю форму
+func (in *Input) ask(read func(prompt string) (string, error), prompt string, validators ...validator) (string, error) {
+	for {
+		value, err := read(prompt)
+		if err != nil {
+			return "", err
+		}
+
+		if err = validate(value, validators); err == nil {
+			return value, nil
+		}
+
+		_, _ = fmt.Fprintf(in.out, "  %s, please try again (Ctrl+D to cancel)\n", err)
+	}
+}
+
+func validate(value string, validators []validator) error {
+	for _, v := range validators {
+		if err := v(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// askUint запрашивает целое число из диапазона [min, max]
+func (in *Input) askUint(prompt string, min, max uint) (uint, error) {
+	str, err := in.ask(in.Line, prompt, uintInRange(min, max))
+	if err != nil {
+		return 0, err
+	}
+
+	num, _ := strconv.ParseUint(str, 10, 0)
+
+	return uint(num), nil
+}
+
+// askOptionalUint - как askUint, но пустой ввод означает 0 (параметр не задан)
+func (in *Input) askOptionalUint(prompt string, min, max uint) (uint, error) {
+	str, err := in.ask(in.Line, prompt, optional(uintInRange(min, max)))
+	if err != nil || str == "" {
+		return 0, err
+	}
+
+	num, _ := strconv.ParseUint(str, 10, 0)
+
+	return uint(num), nil
+}
+
+func optional(v validator) validator {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		return v(value)
+	}
+}
+
+func notEmpty(value string) error {
+	if value == "" {
+		return errors.New("value must not be empty")
+	}
+
+	return nil
+}
+
+func uintInRange(min, max uint) validator {
+	return func(value string) error {
+		num, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || uint(num) < min || uint(num) > max {
+			return fmt.Errorf("enter a whole number from %d to %d", min, max)
+		}
+		return nil
+	}
+}
+
+func phoneNumber(value string) error {
+	if len(value) != phoneNumberLen || strings.IndexFunc(value, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+		return fmt.Errorf("phone number must be %d digits, e.g. 89991234567", phoneNumberLen)
+	}
+
+	return nil
+}
+
+// strongPassword - требование сервера (ровно 10 байт: сервер считает длину
+// в байтах, поэтому буква кириллицы занимает 2) плюс буквы и цифры
+func strongPassword(value string) error {
+	if len(value) != passwordLen {
+		return fmt.Errorf("password must be exactly %d bytes long (non-Latin letters take 2 or more)", passwordLen)
+	}
+	if strings.IndexFunc(value, unicode.IsLetter) < 0 || strings.IndexFunc(value, unicode.IsDigit) < 0 {
+		return errors.New("password must contain both letters and digits")
+	}
+
+	return nil
+}
+
+func rarity(value string) error {
+	for _, r := range rarities {
+		if strings.EqualFold(value, r) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("rarity must be one of: %s", strings.Join(rarities, ", "))
+}
+
+// normalizeRarity приводит редкость к написанию, принятому на сервере
+func normalizeRarity(value string) string {
+	for _, r := range rarities {
+		if strings.EqualFold(value, r) {
+			return r
+		}
+	}
+
+	return value
+}
+
+func currentYear() uint {
+	return uint(time.Now().Year())
+}
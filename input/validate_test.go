@@ -0,0 +1,44 @@
+package input
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrongPassword(t *testing.T) {
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{password: "password12", valid: true},
+		{password: "password1", valid: false},
+		{password: "password123", valid: false},
+		{password: "passwordab", valid: false},
+		{password: "1234567890", valid: false},
+		// 10 символов, но 16 байт - сервер такой пароль отклонит
+		{password: "пароль1234", valid: false},
+		{password: "пар1234", valid: true},
+	}
+
+	for _, tt := range tests {
+		if err := strongPassword(tt.password); (err == nil) != tt.valid {
+			t.Errorf("strongPassword(%q) = %v, want valid %v", tt.password, err, tt.valid)
+		}
+	}
+}
+
+func TestProfileNameIsAskedAgain(t *testing.T) {
+	var out strings.Builder
+	in := New(strings.NewReader("work profile\n\nwork-1\n"), &out)
+
+	name, err := in.ProfileName()
+	if err != nil {
+		t.Fatalf("ProfileName: %v", err)
+	}
+	if name != "work-1" {
+		t.Errorf("ProfileName = %q, want %q", name, "work-1")
+	}
+	if got := strings.Count(out.String(), "please try again"); got != 2 {
+		t.Errorf("retries = %d, want 2\n%s", got, out.String())
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+)
+
+// AddBook добавляет новую книгу в каталог (только для администратора)
+func (c *Client) AddBook(ctx context.Context, book dto.BookDTO) error {
+	return c.do(ctx, call{
+		method:     http.MethodPost,
+		path:       "/api/admin/books",
+		body:       book,
+		auth:       true,
+		status:     http.StatusCreated,
+		idempotent: true,
+	}, nil)
+}
+
+// DeleteBook удаляет книгу из каталога (только для администратора)
+func (c *Client) DeleteBook(ctx context.Context, bookID uuid.UUID) error {
+	return c.do(ctx, call{
+		method: http.MethodDelete,
+		path:   fmt.Sprintf("/api/admin/books/%s", bookID.String()),
+		auth:   true,
+		status: http.StatusOK,
+	}, nil)
+}
+
+// ListReservationsByBook возвращает все брони книги (только для администратора)
+func (c *Client) ListReservationsByBook(ctx context.Context, bookID uuid.UUID) ([]*jsonmodels.ReservationModel, error) {
+	var reservations []*jsonmodels.ReservationModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/admin/reservations",
+		query: map[string]string{
+			"book_id": bookID.String(),
+		},
+		auth:   true,
+		status: http.StatusOK,
+	}, &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+)
+
+// ListBooks возвращает страницу каталога, удовлетворяющую параметрам поиска
+func (c *Client) ListBooks(ctx context.Context, params dto.BookParamsDTO) ([]*jsonmodels.BookModel, error) {
+	var books []*jsonmodels.BookModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/books",
+		query:  booksQuery(params),
+		status: http.StatusOK,
+	}, &books)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// booksQuery собирает параметры запроса каталога: только заданные фильтры,
+// limit и offset - всегда
+func booksQuery(params dto.BookParamsDTO) map[string]string {
+	query := map[string]string{
+		"limit":  fmt.Sprintf("%d", params.Limit),
+		"offset": fmt.Sprintf("%d", params.Offset),
+	}
+
+	for key, value := range map[string]string{
+		"title":     params.Title,
+		"author":    params.Author,
+		"publisher": params.Publisher,
+		"rarity":    params.Rarity,
+		"genre":     params.Genre,
+		"language":  params.Language,
+	} {
+		if value != "" {
+			query[key] = value
+		}
+	}
+
+	for key, value := range map[string]uint{
+		"copies_number":   params.CopiesNumber,
+		"publishing_year": params.PublishingYear,
+		"age_limit":       params.AgeLimit,
+	} {
+		if value != 0 {
+			query[key] = fmt.Sprintf("%d", value)
+		}
+	}
+
+	return query
+}
+
+// GetBook возвращает книгу по ее идентификатору
+func (c *Client) GetBook(ctx context.Context, bookID uuid.UUID) (*jsonmodels.BookModel, error) {
+	var book *jsonmodels.BookModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/books/%s", bookID.String()),
+		status: http.StatusOK,
+	}, &book)
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// AddToFavorites добавляет книгу в избранное текущего читателя
+func (c *Client) AddToFavorites(ctx context.Context, bookID uuid.UUID) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/favorites",
+		body:   bookID,
+		auth:   true,
+		status: http.StatusCreated,
+	}, nil)
+}
+
+// GetRatings возвращает отзывы на книгу
+func (c *Client) GetRatings(ctx context.Context, bookID uuid.UUID) ([]*dto.RatingOutputDTO, error) {
+	var ratings []*dto.RatingOutputDTO
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/ratings",
+		query: map[string]string{
+			"book_id": bookID.String(),
+		},
+		status: http.StatusOK,
+	}, &ratings)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
+
+// GetAvgRating возвращает средний рейтинг книги
+func (c *Client) GetAvgRating(ctx context.Context, bookID uuid.UUID) (float32, error) {
+	var avgRating dto.AvgRatingDTO
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/ratings/avg",
+		query: map[string]string{
+			"book_id": bookID.String(),
+		},
+		status: http.StatusOK,
+	}, &avgRating)
+	if err != nil {
+		return -1, err
+	}
+
+	return avgRating.AvgRating, nil
+}
+
+// AddRating публикует отзыв текущего читателя на книгу
+func (c *Client) AddRating(ctx context.Context, rating dto.RatingInputDTO) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/ratings",
+		body:   rating,
+		auth:   true,
+		status: http.StatusCreated,
+	}, nil)
+}
@@ -0,0 +1,272 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const requestTimeout = 10 * time.Second
+
+// Client - типизированный клиент для BookSmart-web-api
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	transport  TransportConfig
+	retry      RetryPolicy
+	logger     *slog.Logger
+
+	mu       sync.RWMutex
+	tokens   dto.ReaderTokensDTO
+	issuedAt time.Time
+	onTokens func(tokens dto.ReaderTokensDTO, issuedAt time.Time)
+
+	// refreshMu объединяет одновременные обновления токенов в одно
+	refreshMu sync.Mutex
+}
+
+// Option - необязательная настройка клиента
+type Option func(*Client)
+
+// WithRetryPolicy задает политику повторов запросов
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithLogger задает логгер; каждая попытка запроса пишется на уровне Debug
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithTokensListener задает функцию, которая вызывается при каждой смене токенов
+// (вход, обновление, выход), например чтобы сохранить сессию
+func WithTokensListener(listener func(tokens dto.ReaderTokensDTO, issuedAt time.Time)) Option {
+	return func(c *Client) {
+		c.onTokens = listener
+	}
+}
+
+// NewClient создает клиент для API, доступного по baseURL. Все запросы
+// клиента идут через один транспорт, поэтому соединения переиспользуются
+func NewClient(baseURL string, transport TransportConfig, opts ...Option) (*Client, error) {
+	httpClient, err := newHTTPClient(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+		transport:  transport,
+		retry:      DefaultRetryPolicy(),
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// BaseURL возвращает адрес API, с которым работает клиент
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Tokens возвращает текущую пару токенов
+func (c *Client) Tokens() dto.ReaderTokensDTO {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokens
+}
+
+// SetTokens заменяет текущую пару токенов, полученную только что
+func (c *Client) SetTokens(tokens dto.ReaderTokensDTO) {
+	issuedAt := time.Now()
+
+	c.mu.Lock()
+	c.tokens = tokens
+	c.issuedAt = issuedAt
+	c.mu.Unlock()
+
+	if c.onTokens != nil {
+		c.onTokens(tokens, issuedAt)
+	}
+}
+
+// RestoreTokens восстанавливает ранее полученную пару токенов вместе с моментом
+// ее получения, не оповещая WithTokensListener
+func (c *Client) RestoreTokens(tokens dto.ReaderTokensDTO, issuedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = tokens
+	c.issuedAt = issuedAt
+}
+
+// TokensIssuedAt возвращает момент получения текущей пары токенов. Сервер
+// выдает новый refresh-токен при каждом обновлении, поэтому от этого момента
+// отсчитывается и срок жизни refresh-токена
+func (c *Client) TokensIssuedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.issuedAt
+}
+
+// IsAuthenticated сообщает, есть ли у клиента токен доступа
+func (c *Client) IsAuthenticated() bool {
+	return c.Tokens().AccessToken != ""
+}
+
+// Ping проверяет, что по адресу клиента отвечает BookSmart-web-api. Пустой
+// каталог сервер отдает как 404 с JSON-сообщением, а неверный адрес или
+// префикс - как 404 с текстом, поэтому учитывается и формат тела
+func (c *Client) Ping(ctx context.Context) error {
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/books",
+		query: map[string]string{
+			"limit":  "1",
+			"offset": "0",
+		},
+		status: http.StatusOK,
+	}, nil)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && json.Valid(apiErr.Body) {
+		return nil
+	}
+
+	return err
+}
+
+// call - описание одного обращения к API
+type call struct {
+	method string
+	path   string
+	query  map[string]string
+	body   interface{}
+	auth   bool
+	status int
+
+	// idempotent - POST, который можно повторить, приложив Idempotency-Key
+	idempotent bool
+}
+
+// do выполняет обращение к API и декодирует тело успешного ответа в out
+func (c *Client) do(ctx context.Context, cl call, out interface{}) error {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	accessToken := c.Tokens().AccessToken
+	if cl.auth {
+		if accessToken == "" {
+			return ErrNotAuthenticated
+		}
+		headers["Authorization"] = bearer(accessToken)
+	}
+
+	if cl.idempotent && c.retry.IdempotencyKeys {
+		headers[idempotencyKeyHeader] = uuid.NewString()
+	}
+
+	request := HTTPRequest{
+		Method:      cl.method,
+		URL:         c.baseURL + cl.path,
+		Headers:     headers,
+		Body:        cl.body,
+		QueryParams: cl.query,
+		Timeout:     c.transport.timeoutFor(cl.path),
+	}
+
+	response, err := c.send(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	// токен доступа истек: обновляем пару токенов и повторяем запрос один раз.
+	// Сервер отклоняет такой запрос до обработчика, поэтому повтор безопасен и для POST
+	if cl.auth && response.StatusCode == http.StatusUnauthorized {
+		c.logger.Debug("access token rejected, refreshing", "method", request.Method, "url", request.URL)
+
+		if err = c.refreshRejected(ctx, accessToken); err != nil {
+			return err
+		}
+
+		request.Headers["Authorization"] = bearer(c.Tokens().AccessToken)
+		if response, err = c.send(ctx, request); err != nil {
+			return err
+		}
+	}
+
+	if response.StatusCode != cl.status {
+		return newAPIError(cl.method, request.URL, response)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	return json.Unmarshal(response.Body, out)
+}
+
+// send отправляет запрос, повторяя его при временных сбоях согласно политике
+func (c *Client) send(ctx context.Context, request HTTPRequest) (*HTTPResponse, error) {
+	retryable := canRetry(request)
+
+	for attempt := 0; ; attempt++ {
+		response, err := SendRequest(ctx, c.httpClient, request)
+
+		last := !retryable || attempt+1 >= c.retry.MaxAttempts
+		delay := c.retry.backoff(attempt)
+
+		switch {
+		case err != nil:
+			c.logger.Debug("api request failed",
+				"method", request.Method, "url", request.URL, "attempt", attempt+1, "error", err)
+			if last || !isTransient(ctx, err) {
+				return nil, err
+			}
+		case c.retry.retryStatus(response.StatusCode):
+			c.logger.Debug("api request got retryable status",
+				"method", request.Method, "url", request.URL, "attempt", attempt+1, "status", response.StatusCode)
+			if last {
+				return response, nil
+			}
+			if after, ok := retryAfter(response.Headers); ok {
+				// сервер просит ждать дольше, чем мы готовы - отдаем ответ как есть
+				if after > c.retry.MaxDelay {
+					return response, nil
+				}
+				delay = after
+			}
+		default:
+			c.logger.Debug("api request done",
+				"method", request.Method, "url", request.URL, "attempt", attempt+1, "status", response.StatusCode)
+			return response, nil
+		}
+
+		c.logger.Debug("retrying api request", "method", request.Method, "url", request.URL, "delay", delay)
+
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func bearer(accessToken string) string {
+	return fmt.Sprintf("Bearer %s", accessToken)
+}
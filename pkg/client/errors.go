@@ -0,0 +1,100 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotAuthenticated - у клиента нет токена доступа, либо сервер его отклонил (401)
+	ErrNotAuthenticated = errors.New("you are not authenticated")
+
+	// ErrSessionExpired - сервер отклонил refresh-токен, нужно войти заново
+	ErrSessionExpired = errors.New("your session has expired, please sign in again")
+)
+
+// APIError - ответ сервера с кодом, отличным от ожидаемого
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is позволяет проверять ответ 401 через errors.Is(err, ErrNotAuthenticated)
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotAuthenticated && e.StatusCode == http.StatusUnauthorized
+}
+
+// IsUnauthorized сообщает, что сервер отклонил токен доступа (401)
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden сообщает, что у пользователя недостаточно прав (403)
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsNotFound сообщает, что запрошенный объект не найден (404)
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict сообщает, что операция противоречит состоянию на сервере (409)
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// newAPIError собирает ошибку из ответа сервера. Сервер обычно присылает
+// сообщение JSON-строкой, но прокси и сам gin (на неизвестный маршрут)
+// отвечают обычным текстом или HTML, поэтому тело сохраняется как есть
+func newAPIError(method, url string, response *HTTPResponse) *APIError {
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		Method:     method,
+		URL:        url,
+		Body:       response.Body,
+	}
+
+	var info string
+	if err := json.Unmarshal(response.Body, &info); err == nil {
+		apiErr.Message = info
+		return apiErr
+	}
+
+	var obj struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(response.Body, &obj); err == nil {
+		apiErr.Message = obj.Message
+		if apiErr.Message == "" {
+			apiErr.Message = obj.Error
+		}
+		return apiErr
+	}
+
+	if text := strings.TrimSpace(string(response.Body)); !strings.HasPrefix(text, "<") {
+		apiErr.Message = text
+	}
+
+	return apiErr
+}
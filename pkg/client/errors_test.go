@@ -0,0 +1,94 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantMessage string
+		wantError   string
+	}{
+		{
+			name:        "json string",
+			status:      http.StatusConflict,
+			body:        `"book is already reserved"`,
+			wantMessage: "book is already reserved",
+			wantError:   "book is already reserved",
+		},
+		{
+			name:        "json object with message",
+			status:      http.StatusBadRequest,
+			body:        `{"message": "invalid phone number"}`,
+			wantMessage: "invalid phone number",
+			wantError:   "invalid phone number",
+		},
+		{
+			name:        "json object with error",
+			status:      http.StatusBadRequest,
+			body:        `{"error": "invalid page"}`,
+			wantMessage: "invalid page",
+			wantError:   "invalid page",
+		},
+		{
+			name:        "plain text",
+			status:      http.StatusNotFound,
+			body:        "404 page not found\n",
+			wantMessage: "404 page not found",
+			wantError:   "404 page not found",
+		},
+		{
+			name:      "html",
+			status:    http.StatusBadGateway,
+			body:      "<html><body>Bad Gateway</body></html>",
+			wantError: "GET http://api/books: 502 Bad Gateway",
+		},
+		{
+			name:      "empty body",
+			status:    http.StatusInternalServerError,
+			wantError: "GET http://api/books: 500 Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := newAPIError(http.MethodGet, "http://api/books", &HTTPResponse{
+				StatusCode: tt.status,
+				Body:       []byte(tt.body),
+			})
+
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if apiErr.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", apiErr.Message, tt.wantMessage)
+			}
+			if apiErr.Error() != tt.wantError {
+				t.Errorf("Error() = %q, want %q", apiErr.Error(), tt.wantError)
+			}
+			if string(apiErr.Body) != tt.body {
+				t.Errorf("Body = %q, want %q", apiErr.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestAPIErrorStatusHelpers(t *testing.T) {
+	unauthorized := error(&APIError{StatusCode: http.StatusUnauthorized})
+	notFound := error(&APIError{StatusCode: http.StatusNotFound})
+
+	if !errors.Is(unauthorized, ErrNotAuthenticated) || errors.Is(notFound, ErrNotAuthenticated) {
+		t.Error("only 401 should match ErrNotAuthenticated")
+	}
+	if !IsUnauthorized(unauthorized) || IsUnauthorized(notFound) {
+		t.Error("IsUnauthorized should match only 401")
+	}
+	if !IsNotFound(notFound) || IsNotFound(errors.New("404")) {
+		t.Error("IsNotFound should match only a 404 APIError")
+	}
+}
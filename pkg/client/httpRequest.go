@@ -1,7 +1,8 @@
-package requesters
+package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,8 +29,8 @@ type HTTPResponse struct {
 	Body       []byte
 }
 
-// SendRequest - универсальная функция для отправки HTTP-запроса
-func SendRequest(req HTTPRequest) (*HTTPResponse, error) {
+// SendRequest - универсальная функция для отправки HTTP-запроса через client
+func SendRequest(ctx context.Context, client *http.Client, req HTTPRequest) (*HTTPResponse, error) {
 	// Создаем URL с параметрами запроса
 	parsedURL, err := url.Parse(req.URL)
 	if err != nil {
@@ -55,8 +56,15 @@ func SendRequest(req HTTPRequest) (*HTTPResponse, error) {
 		body = bytes.NewBuffer(jsonBody)
 	}
 
+	// Ограничиваем время выполнения запроса
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
 	// Создаем новый HTTP-запрос
-	httpReq, err := http.NewRequest(req.Method, parsedURL.String(), body)
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, parsedURL.String(), body)
 	if err != nil {
 		return nil, err
 	}
@@ -66,11 +74,6 @@ func SendRequest(req HTTPRequest) (*HTTPResponse, error) {
 		httpReq.Header.Add(key, value)
 	}
 
-	// Устанавливаем таймаут для HTTP-клиента
-	client := &http.Client{
-		Timeout: req.Timeout,
-	}
-
 	// Отправляем запрос
 	resp, err := client.Do(httpReq)
 	if err != nil {
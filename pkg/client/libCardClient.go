@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+)
+
+// CreateLibCard оформляет читательский билет текущему читателю
+func (c *Client) CreateLibCard(ctx context.Context) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/api/lib-cards",
+		auth:   true,
+		status: http.StatusCreated,
+	}, nil)
+}
+
+// UpdateLibCard продлевает читательский билет текущего читателя
+func (c *Client) UpdateLibCard(ctx context.Context) error {
+	return c.do(ctx, call{
+		method: http.MethodPut,
+		path:   "/api/lib-cards",
+		auth:   true,
+		status: http.StatusOK,
+	}, nil)
+}
+
+// GetLibCard возвращает читательский билет текущего читателя
+func (c *Client) GetLibCard(ctx context.Context) (*jsonmodels.LibCardModel, error) {
+	var libCard *jsonmodels.LibCardModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/lib-cards",
+		auth:   true,
+		status: http.StatusOK,
+	}, &libCard)
+	if err != nil {
+		return nil, err
+	}
+
+	return libCard, nil
+}
@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+)
+
+// SignUp регистрирует нового читателя
+func (c *Client) SignUp(ctx context.Context, params dto.ReaderSignUpDTO) error {
+	return c.do(ctx, call{
+		method: http.MethodPost,
+		path:   "/auth/sign-up",
+		body:   params,
+		status: http.StatusCreated,
+	}, nil)
+}
+
+// SignIn аутентифицирует читателя и сохраняет полученные токены в клиенте
+func (c *Client) SignIn(ctx context.Context, params dto.ReaderSignInDTO) (dto.ReaderTokensDTO, error) {
+	return c.signIn(ctx, "/auth/sign-in", params)
+}
+
+// SignInAsAdmin аутентифицирует администратора и сохраняет полученные токены в клиенте
+func (c *Client) SignInAsAdmin(ctx context.Context, params dto.ReaderSignInDTO) (dto.ReaderTokensDTO, error) {
+	return c.signIn(ctx, "/auth/admin/sign-in", params)
+}
+
+func (c *Client) signIn(ctx context.Context, path string, params dto.ReaderSignInDTO) (dto.ReaderTokensDTO, error) {
+	var tokens dto.ReaderTokensDTO
+	err := c.do(ctx, call{
+		method: http.MethodPost,
+		path:   path,
+		body:   params,
+		status: http.StatusOK,
+	}, &tokens)
+	if err != nil {
+		return dto.ReaderTokensDTO{}, err
+	}
+
+	c.SetTokens(tokens)
+
+	return tokens, nil
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов. Если сервер
+// отклоняет refresh-токен, токены забываются и возвращается ErrSessionExpired
+func (c *Client) Refresh(ctx context.Context) (dto.ReaderTokensDTO, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	return c.refresh(ctx)
+}
+
+// refreshRejected обновляет токены после того, как сервер отклонил rejected.
+// Если за время ожидания токены уже обновил другой запрос, повторно не обновляет
+func (c *Client) refreshRejected(ctx context.Context, rejected string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if c.Tokens().AccessToken != rejected {
+		return nil
+	}
+
+	_, err := c.refresh(ctx)
+	return err
+}
+
+func (c *Client) refresh(ctx context.Context) (dto.ReaderTokensDTO, error) {
+	refreshToken := c.Tokens().RefreshToken
+	if refreshToken == "" {
+		return dto.ReaderTokensDTO{}, ErrNotAuthenticated
+	}
+
+	// сервер не обрабатывает запрос, на который ответил 429 или 503, поэтому
+	// обновление повторяется по общей политике, как и другие создающие запросы
+	var tokens dto.ReaderTokensDTO
+	err := c.do(ctx, call{
+		method:     http.MethodPost,
+		path:       "/auth/refresh",
+		body:       refreshToken,
+		status:     http.StatusOK,
+		idempotent: true,
+	}, &tokens)
+
+	if refreshTokenRejected(err) {
+		c.logger.Debug("refresh token rejected", "error", err)
+		c.SignOut()
+		return dto.ReaderTokensDTO{}, ErrSessionExpired
+	}
+	if err != nil {
+		return dto.ReaderTokensDTO{}, err
+	}
+
+	c.SetTokens(tokens)
+
+	return tokens, nil
+}
+
+// refreshTokenRejected сообщает, что сервер не принял refresh-токен. Неизвестный
+// или истекший токен сервер отклоняет кодом 404, прокси перед ним - 401 или 403.
+// Остальные ошибки (429, 408, 5xx) временные, и сессия после них сохраняется
+func refreshTokenRejected(err error) bool {
+	return IsUnauthorized(err) || IsForbidden(err) || IsNotFound(err)
+}
+
+// SignOut забывает токены текущего пользователя
+func (c *Client) SignOut() {
+	c.SetTokens(dto.ReaderTokensDTO{})
+}
+
+// GetReaderByPhoneNumber возвращает читателя с указанным номером телефона
+func (c *Client) GetReaderByPhoneNumber(ctx context.Context, phoneNumber string) (*jsonmodels.ReaderModel, error) {
+	var reader *jsonmodels.ReaderModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/readers",
+		query: map[string]string{
+			"phone_number": phoneNumber,
+		},
+		auth:   true,
+		status: http.StatusOK,
+	}, &reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshStatuses(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      error
+		wantAPIError int
+		wantSignOut  bool
+		wantCalls    int
+	}{
+		{name: "unknown refresh token", statuses: []int{http.StatusNotFound}, wantErr: ErrSessionExpired, wantSignOut: true, wantCalls: 1},
+		{name: "unauthorized", statuses: []int{http.StatusUnauthorized}, wantErr: ErrSessionExpired, wantSignOut: true, wantCalls: 1},
+		{name: "forbidden", statuses: []int{http.StatusForbidden}, wantErr: ErrSessionExpired, wantSignOut: true, wantCalls: 1},
+		{name: "bad request keeps session", statuses: []int{http.StatusBadRequest}, wantAPIError: http.StatusBadRequest, wantCalls: 1},
+		{name: "timeout keeps session", statuses: []int{http.StatusRequestTimeout}, wantAPIError: http.StatusRequestTimeout, wantCalls: 1},
+		{name: "server error keeps session", statuses: []int{http.StatusInternalServerError}, wantAPIError: http.StatusInternalServerError, wantCalls: 1},
+		{name: "too many requests is retried", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, wantCalls: 2},
+		{
+			name:         "too many requests until attempts run out",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			wantAPIError: http.StatusTooManyRequests,
+			wantCalls:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			policy := testPolicy()
+			policy.IdempotencyKeys = true
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					_ = json.NewEncoder(w).Encode(dto.ReaderTokensDTO{AccessToken: "new-access", RefreshToken: "new-refresh"})
+				}
+			}, policy)
+
+			tokens, err := c.Refresh(context.Background())
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantAPIError != 0:
+				if !hasStatus(err, tt.wantAPIError) {
+					t.Fatalf("err = %v, want APIError %d", err, tt.wantAPIError)
+				}
+			default:
+				if err != nil || tokens.AccessToken != "new-access" || c.Tokens().RefreshToken != "new-refresh" {
+					t.Fatalf("Refresh = %+v, %v; want new tokens", tokens, err)
+				}
+			}
+
+			if signedOut := !c.IsAuthenticated(); signedOut != tt.wantSignOut {
+				t.Errorf("signed out = %v, want %v", signedOut, tt.wantSignOut)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// authServer отвечает 401 на запросы со старым токеном доступа и выдает
+// новую пару токенов на /auth/refresh
+type authServer struct {
+	refreshes atomic.Int32
+	requests  atomic.Int32
+	release   chan struct{}
+}
+
+func (s *authServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/auth/refresh" {
+		s.refreshes.Add(1)
+		if s.release != nil {
+			<-s.release
+		}
+		_ = json.NewEncoder(w).Encode(dto.ReaderTokensDTO{AccessToken: "new-access", RefreshToken: "new-refresh"})
+		return
+	}
+
+	s.requests.Add(1)
+	if r.Header.Get("Authorization") != bearer("new-access") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_, _ = w.Write([]byte("[]"))
+}
+
+func TestUnauthorizedRequestIsReplayedAfterRefresh(t *testing.T) {
+	server := &authServer{}
+	c := newTestClient(t, server.ServeHTTP, testPolicy())
+
+	if _, err := c.ListReservations(context.Background()); err != nil {
+		t.Fatalf("ListReservations: %v", err)
+	}
+
+	if got := server.refreshes.Load(); got != 1 {
+		t.Errorf("refreshes = %d, want 1", got)
+	}
+	if got := server.requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2 (rejected and replayed)", got)
+	}
+	if got := c.Tokens().AccessToken; got != "new-access" {
+		t.Errorf("access token = %q, want %q", got, "new-access")
+	}
+}
+
+func TestConcurrentUnauthorizedRequestsRefreshOnce(t *testing.T) {
+	const n = 5
+
+	server := &authServer{release: make(chan struct{})}
+	c := newTestClient(t, server.ServeHTTP, testPolicy())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.ListReservations(context.Background())
+			errs <- err
+		}()
+	}
+
+	// держим первое обновление, пока все запросы не получат 401
+	for server.requests.Load() < n {
+		time.Sleep(time.Millisecond)
+	}
+	close(server.release)
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("ListReservations: %v", err)
+		}
+	}
+
+	if got := server.refreshes.Load(); got != 1 {
+		t.Errorf("refreshes = %d, want 1", got)
+	}
+}
+
+func TestRefreshRejectedSkipsAlreadyRefreshedToken(t *testing.T) {
+	server := &authServer{}
+	c := newTestClient(t, server.ServeHTTP, testPolicy())
+
+	if err := c.refreshRejected(context.Background(), "stale-access"); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.refreshes.Load(); got != 0 {
+		t.Errorf("refreshes = %d, want 0", got)
+	}
+}
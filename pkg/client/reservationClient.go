@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+)
+
+// Reserve бронирует книгу для текущего читателя
+func (c *Client) Reserve(ctx context.Context, bookID uuid.UUID) error {
+	return c.do(ctx, call{
+		method:     http.MethodPost,
+		path:       "/api/reservations",
+		body:       bookID,
+		auth:       true,
+		status:     http.StatusCreated,
+		idempotent: true,
+	}, nil)
+}
+
+// ListReservations возвращает все брони текущего читателя
+func (c *Client) ListReservations(ctx context.Context) ([]*jsonmodels.ReservationModel, error) {
+	var reservations []*jsonmodels.ReservationModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   "/api/reservations",
+		auth:   true,
+		status: http.StatusOK,
+	}, &reservations)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservations, nil
+}
+
+// GetReservation возвращает бронь по ее идентификатору
+func (c *Client) GetReservation(ctx context.Context, reservationID uuid.UUID) (*jsonmodels.ReservationModel, error) {
+	var reservation *jsonmodels.ReservationModel
+	err := c.do(ctx, call{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/api/reservations/%s", reservationID.String()),
+		auth:   true,
+		status: http.StatusOK,
+	}, &reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// ExtendReservation продлевает бронь
+func (c *Client) ExtendReservation(ctx context.Context, reservationID uuid.UUID) error {
+	return c.do(ctx, call{
+		method: http.MethodPut,
+		path:   fmt.Sprintf("/api/reservations/%s", reservationID.String()),
+		body:   reservationID,
+		auth:   true,
+		status: http.StatusOK,
+	}, nil)
+}
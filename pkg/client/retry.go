@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy - правила повтора запросов при временных сбоях
+type RetryPolicy struct {
+	// MaxAttempts - общее число попыток, включая первую; 1 отключает повторы
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+
+	// RetryStatuses - коды ответа, после которых запрос повторяется
+	RetryStatuses []int
+
+	// IdempotencyKeys включает заголовок Idempotency-Key для POST-запросов,
+	// создающих объекты (бронь, книга). Без ключа POST не повторяется
+	IdempotencyKeys bool
+}
+
+// DefaultRetryPolicy возвращает политику повторов по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// canRetry сообщает, безопасно ли повторять запрос
+func canRetry(req HTTPRequest) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	_, ok := req.Headers[idempotencyKeyHeader]
+	return ok
+}
+
+// isTransient сообщает, что ошибка транспорта вызвана временным сбоем
+func isTransient(ctx context.Context, err error) bool {
+	// отмена вызывающей стороной - не сбой
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (p RetryPolicy) retryStatus(statusCode int) bool {
+	for _, s := range p.RetryStatuses {
+		if s == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff возвращает задержку перед попыткой attempt (с нуля) - экспоненциальную,
+// со случайным разбросом, чтобы клиенты не повторяли запросы синхронно
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return ceiling/2 + time.Duration(rand.Int63n(int64(ceiling/2)+1))
+}
+
+// retryAfter разбирает заголовок Retry-After (секунды или HTTP-дата)
+func retryAfter(headers map[string][]string) (time.Duration, bool) {
+	values := http.Header(headers).Values("Retry-After")
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(values[0]); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(values[0]); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// sleep ждет delay или отмены ctx
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testPolicy - политика повторов с короткими задержками, чтобы тесты не ждали
+func testPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 50 * time.Millisecond
+	return policy
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, policy RetryPolicy) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(server.URL, DefaultTransportConfig(), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.RestoreTokens(dto.ReaderTokensDTO{AccessToken: "access", RefreshToken: "refresh"}, time.Now())
+
+	return c
+}
+
+func TestBackoffBounds(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 0, ceiling: 100 * time.Millisecond},
+		{attempt: 1, ceiling: 200 * time.Millisecond},
+		{attempt: 3, ceiling: 800 * time.Millisecond},
+		{attempt: 4, ceiling: time.Second},
+		{attempt: 62, ceiling: time.Second}, // сдвиг переполняет Duration
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			delay := policy.backoff(tt.attempt)
+			if delay < tt.ceiling/2 || delay > tt.ceiling {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v]", tt.attempt, delay, tt.ceiling/2, tt.ceiling)
+			}
+		}
+	}
+
+	if delay := (RetryPolicy{}).backoff(0); delay != 0 {
+		t.Errorf("backoff without delays = %v, want 0", delay)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "missing"},
+		{name: "seconds", header: []string{"3"}, want: 3 * time.Second, wantOK: true},
+		{name: "zero", header: []string{"0"}, want: 0, wantOK: true},
+		{name: "negative", header: []string{"-1"}},
+		{name: "garbage", header: []string{"soon"}},
+		{name: "past date", header: []string{"Mon, 02 Jan 2006 15:04:05 GMT"}, want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string][]string{}
+			if tt.header != nil {
+				headers["Retry-After"] = tt.header
+			}
+
+			got, ok := retryAfter(headers)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%v) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	t.Run("future date", func(t *testing.T) {
+		date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+
+		got, ok := retryAfter(map[string][]string{"Retry-After": {date}})
+		if !ok || got <= 58*time.Second || got > time.Minute {
+			t.Errorf("retryAfter(%q) = %v, %v; want about a minute", date, got, ok)
+		}
+	})
+}
+
+func TestSendRetriesStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		wantCalls  int
+		wantStatus int
+	}{
+		{
+			name:       "recovers after transient failures",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantCalls:  3,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "gives up after max attempts",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantCalls:  3,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "does not retry other statuses",
+			statuses:   []int{http.StatusInternalServerError, http.StatusOK},
+			wantCalls:  1,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "honours short Retry-After",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "0",
+			wantCalls:  2,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "returns response when Retry-After exceeds MaxDelay",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "120",
+			wantCalls:  1,
+			wantStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}, testPolicy())
+
+			response, err := c.send(context.Background(), HTTPRequest{
+				Method: http.MethodGet,
+				URL:    c.BaseURL() + "/books",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if response.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestSendStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls int
+	policy := testPolicy()
+	policy.BaseDelay, policy.MaxDelay = time.Minute, time.Minute
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, policy)
+
+	_, err := c.send(ctx, HTTPRequest{Method: http.MethodGet, URL: c.BaseURL() + "/books"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestIdempotencyKeyGatesPostRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      bool
+		wantCalls int
+	}{
+		{name: "without keys POST is sent once", keys: false, wantCalls: 1},
+		{name: "with keys POST is retried", keys: true, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				mu   sync.Mutex
+				keys []string
+			)
+			policy := testPolicy()
+			policy.IdempotencyKeys = tt.keys
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				keys = append(keys, r.Header.Get(idempotencyKeyHeader))
+				mu.Unlock()
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}, policy)
+
+			err := c.Reserve(context.Background(), uuid.New())
+			if !hasStatus(err, http.StatusServiceUnavailable) {
+				t.Fatalf("Reserve: %v, want 503 APIError", err)
+			}
+
+			if len(keys) != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", len(keys), tt.wantCalls)
+			}
+			for _, key := range keys {
+				if tt.keys && (key == "" || key != keys[0]) {
+					t.Errorf("Idempotency-Key = %q, want the same key on every attempt (first %q)", key, keys[0])
+				}
+				if !tt.keys && key != "" {
+					t.Errorf("Idempotency-Key = %q, want none", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"strings"
+	"time"
+)
+
+// AccessTokenExpiry возвращает момент истечения токена доступа. Срок берется
+// из claim exp (подпись не проверяется - это делает сервер), а если токен
+// не удалось разобрать - из поля ExpiredAt, которое сервер отдает вместе с токенами
+func AccessTokenExpiry(tokens dto.ReaderTokensDTO) (time.Time, bool) {
+	if exp, err := jwtExpiry(tokens.AccessToken); err == nil {
+		return exp, true
+	}
+
+	if tokens.ExpiredAt > 0 {
+		return time.UnixMilli(tokens.ExpiredAt), true
+	}
+
+	return time.Time{}, false
+}
+
+// ReaderID возвращает ID вошедшего пользователя - claim sub токена доступа
+func ReaderID(tokens dto.ReaderTokensDTO) (string, error) {
+	var claims struct {
+		Sub string `json:"sub"`
+	}
+	if err := jwtClaims(tokens.AccessToken, &claims); err != nil {
+		return "", err
+	}
+	if claims.Sub == "" {
+		return "", errors.New("token has no sub claim")
+	}
+
+	return claims.Sub, nil
+}
+
+func jwtExpiry(token string) (time.Time, error) {
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := jwtClaims(token, &claims); err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == nil {
+		return time.Time{}, errors.New("token has no exp claim")
+	}
+
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(int64(exp), 0), nil
+}
+
+// jwtClaims разбирает полезную нагрузку JWT без проверки подписи
+func jwtClaims(token string, claims interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(payload, claims)
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// TransportConfig - настройки HTTP-транспорта, общего для всех запросов клиента
+type TransportConfig struct {
+	MaxIdleConns          int
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// RequestTimeout ограничивает запрос целиком, EndpointTimeouts
+	// переопределяет его для путей с указанным префиксом ("/books", "/api/admin")
+	RequestTimeout   time.Duration
+	EndpointTimeouts map[string]time.Duration
+
+	ProxyFromEnvironment bool
+	CAFile               string
+	InsecureSkipVerify   bool
+}
+
+// DefaultTransportConfig возвращает настройки транспорта по умолчанию
+func DefaultTransportConfig() TransportConfig {
+	return TransportConfig{
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		DialTimeout:           5 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		RequestTimeout:        requestTimeout,
+		ProxyFromEnvironment:  true,
+	}
+}
+
+// timeoutFor возвращает таймаут для пути: самый длинный подходящий префикс
+// из EndpointTimeouts, иначе RequestTimeout
+func (cfg TransportConfig) timeoutFor(path string) time.Duration {
+	timeout, matched := cfg.RequestTimeout, ""
+	for prefix, t := range cfg.EndpointTimeouts {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			timeout, matched = t, prefix
+		}
+	}
+
+	return timeout
+}
+
+func newHTTPClient(cfg TransportConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.CAFile != "" {
+		pool, err := loadCertPool(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+	}
+
+	if cfg.ProxyFromEnvironment {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+
+	// общий таймаут задается на каждый запрос через контекст, см. SendRequest
+	return &http.Client{Transport: transport}, nil
+}
+
+// loadCertPool добавляет к системным корневым сертификатам сертификаты из caFile
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("CA bundle contains no valid PEM certificates")
+	}
+
+	return pool, nil
+}
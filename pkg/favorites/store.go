@@ -0,0 +1,156 @@
+package favorites
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Сервер умеет только добавлять книгу в избранное, поэтому список избранного
+// ведется локально: для каждого аккаунта (адрес API и ID читателя) - в порядке,
+// заданном пользователем
+
+var ErrNotFound = errors.New("book is not in favorites")
+
+// Favorite - книга в избранном; название и автор запоминаются при добавлении
+type Favorite struct {
+	BookID  uuid.UUID `json:"book_id"`
+	Title   string    `json:"title"`
+	Author  string    `json:"author"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type IFavoritesStore interface {
+	List(owner string) ([]Favorite, error)
+	Add(owner string, f Favorite) error
+	Remove(owner string, bookID uuid.UUID) error
+	Move(owner string, bookID uuid.UUID, position int) error
+}
+
+// FileStore хранит избранное всех аккаунтов в одном JSON-файле
+type FileStore struct {
+	path string
+}
+
+// NewFileStore создает хранилище избранного в файле path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// List возвращает избранное аккаунта owner в порядке пользователя
+func (fs *FileStore) List(owner string) ([]Favorite, error) {
+	all, err := fs.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if list := all[owner]; list != nil {
+		return list, nil
+	}
+
+	return []Favorite{}, nil
+}
+
+// Add добавляет книгу в конец списка; уже добавленная книга остается на месте
+func (fs *FileStore) Add(owner string, f Favorite) error {
+	all, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	if index(all[owner], f.BookID) >= 0 {
+		return nil
+	}
+	all[owner] = append(all[owner], f)
+
+	return fs.save(all)
+}
+
+// Remove убирает книгу из списка
+func (fs *FileStore) Remove(owner string, bookID uuid.UUID) error {
+	all, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	i := index(all[owner], bookID)
+	if i < 0 {
+		return ErrNotFound
+	}
+	all[owner] = append(all[owner][:i], all[owner][i+1:]...)
+
+	return fs.save(all)
+}
+
+// Move переставляет книгу на место position (с нуля); позиция за концом
+// списка означает последнее место
+func (fs *FileStore) Move(owner string, bookID uuid.UUID, position int) error {
+	all, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	list := all[owner]
+	i := index(list, bookID)
+	if i < 0 {
+		return ErrNotFound
+	}
+	if position < 0 {
+		return fmt.Errorf("invalid position %d", position)
+	}
+	if position >= len(list) {
+		position = len(list) - 1
+	}
+
+	f := list[i]
+	list = append(list[:i], list[i+1:]...)
+	list = append(list[:position], append([]Favorite{f}, list[position:]...)...)
+	all[owner] = list
+
+	return fs.save(all)
+}
+
+func index(list []Favorite, bookID uuid.UUID) int {
+	for i, f := range list {
+		if f.BookID == bookID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (fs *FileStore) load() (map[string][]Favorite, error) {
+	all := make(map[string][]Favorite)
+
+	data, err := os.ReadFile(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parsing favorites file %s: %w", fs.path, err)
+	}
+
+	return all, nil
+}
+
+func (fs *FileStore) save(all map[string][]Favorite) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fs.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.path, data, 0o600)
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"gopkg.in/yaml.v3"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Format - формат вывода списков и карточек
+type Format string
+
+const (
+	Table    Format = "table"
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	CSV      Format = "csv"
+	YAML     Format = "yaml"
+	Markdown Format = "markdown"
+)
+
+var formats = []Format{Table, JSON, NDJSON, CSV, YAML, Markdown}
+
+// ParseFormat проверяет название формата
+func ParseFormat(name string) (Format, error) {
+	for _, f := range formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+
+	return "", fmt.Errorf("unknown output format %q, expected one of: %s", name, strings.Join(names, ", "))
+}
+
+// FormatForFile выбирает формат по расширению файла
+func FormatForFile(path string) (Format, error) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	switch ext {
+	case "md":
+		return Markdown, nil
+	case "yml":
+		return YAML, nil
+	case "json", "ndjson", "csv", "yaml", "markdown":
+		return ParseFormat(ext)
+	default:
+		return "", fmt.Errorf("cannot choose format for %q, use .json, .ndjson, .csv, .yaml or .md", path)
+	}
+}
+
+// Tabular сообщает, строится ли вывод из таблицы go-pretty
+func (f Format) Tabular() bool {
+	return f == Table || f == CSV || f == Markdown
+}
+
+// Render выводит представление в формате f: табличные форматы берут таблицу t,
+// остальные сериализуют data - те же данные, что пришли от API
+func Render(w io.Writer, f Format, t table.Writer, data interface{}) error {
+	var out string
+
+	switch f {
+	case Table, "":
+		out = t.Render()
+	case Markdown:
+		out = t.RenderMarkdown()
+	case CSV:
+		// заголовок таблицы сломал бы CSV для электронных таблиц
+		t.SetTitle("")
+		out = t.RenderCSV()
+	case JSON:
+		b, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = string(b)
+	case NDJSON:
+		return writeNDJSON(w, data)
+	case YAML:
+		b, err := marshalYAML(data)
+		if err != nil {
+			return err
+		}
+		out = strings.TrimRight(string(b), "\n")
+	default:
+		return fmt.Errorf("unknown output format %q", f)
+	}
+
+	_, err := fmt.Fprintln(w, out)
+
+	return err
+}
+
+// writeNDJSON пишет каждый элемент списка отдельной строкой JSON
+func writeNDJSON(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return enc.Encode(data)
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// marshalYAML сериализует data через JSON, чтобы имена полей совпадали
+// с JSON-тегами моделей API
+func marshalYAML(data interface{}) ([]byte, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(generic)
+}
@@ -0,0 +1,162 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+var (
+	ErrNotFound    = errors.New("profile not found")
+	ErrInvalidName = errors.New("profile name may contain only letters, digits, '-' and '_'")
+)
+
+var nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Profile - именованная учетная запись: адрес API, роль и ссылка на учетные
+// данные. Сам пароль не хранится - он берется из переменной PasswordEnv или
+// запрашивается при входе
+type Profile struct {
+	Name        string `json:"name"`
+	APIURL      string `json:"api_url,omitempty"`
+	Role        string `json:"role"`
+	PhoneNumber string `json:"phone_number"`
+	PasswordEnv string `json:"password_env,omitempty"`
+}
+
+// Validate проверяет имя профиля: оно используется и в имени файла сессии
+func (p Profile) Validate() error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	if p.Role != session.RoleReader && p.Role != session.RoleAdmin {
+		return fmt.Errorf("unknown profile role %q, expected %s or %s", p.Role, session.RoleReader, session.RoleAdmin)
+	}
+	if p.PhoneNumber == "" {
+		return errors.New("profile phone number is empty")
+	}
+
+	return nil
+}
+
+// ValidateName проверяет имя профиля до того, как заполнена остальная форма
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	return nil
+}
+
+// IProfileStore - хранилище профилей
+type IProfileStore interface {
+	List() ([]Profile, error)
+	Get(name string) (Profile, error)
+	Put(p Profile) error
+	Delete(name string) error
+}
+
+// FileStore хранит профили в JSON-файле
+type FileStore struct {
+	path string
+}
+
+// NewFileStore создает хранилище профилей в файле path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// List возвращает профили, упорядоченные по имени
+func (fs *FileStore) List() ([]Profile, error) {
+	data, err := os.ReadFile(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Profile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []Profile
+	if err = json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("parsing profiles file %s: %w", fs.path, err)
+	}
+
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	return profiles, nil
+}
+
+// Get возвращает профиль по имени
+func (fs *FileStore) Get(name string) (Profile, error) {
+	profiles, err := fs.List()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return Profile{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Put добавляет профиль или заменяет профиль с тем же именем
+func (fs *FileStore) Put(p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	profiles, err := fs.List()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range profiles {
+		if profiles[i].Name == p.Name {
+			profiles[i] = p
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, p)
+	}
+
+	return fs.save(profiles)
+}
+
+// Delete удаляет профиль по имени
+func (fs *FileStore) Delete(name string) error {
+	profiles, err := fs.List()
+	if err != nil {
+		return err
+	}
+
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return fs.save(append(profiles[:i], profiles[i+1:]...))
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+func (fs *FileStore) save(profiles []Profile) error {
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fs.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fs.path, data, 0o600)
+}
@@ -0,0 +1,192 @@
+package search
+
+import (
+	"context"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"strings"
+)
+
+// scanBatch - по сколько книг просматривается каталог, когда часть фильтров
+// проверяется на клиенте
+const scanBatch = 50
+
+// Range - диапазон значений; незаданная граница не ограничивает
+type Range struct {
+	From *uint `json:"from,omitempty"`
+	To   *uint `json:"to,omitempty"`
+}
+
+func (r Range) IsSet() bool {
+	return r.From != nil || r.To != nil
+}
+
+func (r Range) Contains(value uint) bool {
+	return (r.From == nil || value >= *r.From) && (r.To == nil || value <= *r.To)
+}
+
+// Query - параметры поиска книг. Params уходят на сервер (только заданные
+// поля); несколько значений и диапазоны сервер не поддерживает, поэтому они
+// проверяются на клиенте
+type Query struct {
+	Params    dto.BookParamsDTO
+	Genres    []string `json:"genres,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	Year      Range    `json:"year"`
+	AgeLimit  Range    `json:"age_limit"`
+}
+
+// ClientSide сообщает, что часть фильтров проверяется на клиенте
+func (q Query) ClientSide() bool {
+	return len(q.Genres) > 0 || len(q.Languages) > 0 || q.Year.IsSet() || q.AgeLimit.IsSet()
+}
+
+// Matches проверяет книгу по фильтрам, которые не отправляются на сервер
+func (q Query) Matches(book *jsonmodels.BookModel) bool {
+	return oneOf(book.Genre, q.Genres) &&
+		oneOf(book.Language, q.Languages) &&
+		q.Year.Contains(book.PublishingYear) &&
+		q.AgeLimit.Contains(book.AgeLimit)
+}
+
+func oneOf(value string, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		if strings.EqualFold(value, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lister запрашивает у сервера страницу каталога
+type Lister func(ctx context.Context, params dto.BookParamsDTO) ([]*jsonmodels.BookModel, error)
+
+// Page возвращает до limit книг, подходящих под запрос, пропустив первые offset.
+// Если все фильтры понимает сервер, это один запрос; иначе каталог
+// просматривается с начала и фильтруется на клиенте
+func Page(ctx context.Context, list Lister, q Query, offset int, limit uint) ([]*jsonmodels.BookModel, error) {
+	if !q.ClientSide() {
+		params := q.Params
+		params.Limit, params.Offset = limit, offset
+		return list(ctx, params)
+	}
+
+	var (
+		page    []*jsonmodels.BookModel
+		skipped int
+	)
+
+	err := scan(ctx, list, q, func(book *jsonmodels.BookModel) bool {
+		if skipped < offset {
+			skipped++
+			return true
+		}
+		page = append(page, book)
+		return uint(len(page)) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// All возвращает все книги, подходящие под запрос, просматривая каталог
+// порциями по scanBatch
+func All(ctx context.Context, list Lister, q Query) ([]*jsonmodels.BookModel, error) {
+	var books []*jsonmodels.BookModel
+
+	err := scan(ctx, list, q, func(book *jsonmodels.BookModel) bool {
+		books = append(books, book)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// scan передает в yield подходящие под запрос книги по порядку, пока каталог
+// не закончится или yield не вернет false
+func scan(ctx context.Context, list Lister, q Query, yield func(book *jsonmodels.BookModel) bool) error {
+	params := q.Params
+	params.Limit = scanBatch
+
+	for params.Offset = 0; ; params.Offset += scanBatch {
+		books, err := list(ctx, params)
+		// сервер отвечает 404, когда книг больше нет
+		if client.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, book := range books {
+			if q.Matches(book) && !yield(book) {
+				return nil
+			}
+		}
+
+		if len(books) < scanBatch {
+			return nil
+		}
+	}
+}
+
+// Count возвращает число книг, подходящих под запрос. Сервер его не сообщает,
+// поэтому без фильтров на клиенте оно находится пробными запросами по одной
+// книге: смещение удваивается до первого пустого ответа, затем граница
+// уточняется двоичным поиском
+func Count(ctx context.Context, list Lister, q Query) (int, error) {
+	if q.ClientSide() {
+		books, err := All(ctx, list, q)
+		return len(books), err
+	}
+
+	exists := func(offset int) (bool, error) {
+		books, err := Page(ctx, list, q, offset, 1)
+		if client.IsNotFound(err) {
+			return false, nil
+		}
+		return len(books) > 0, err
+	}
+
+	found, err := exists(0)
+	if err != nil || !found {
+		return 0, err
+	}
+
+	// книга с номером low есть, с номером high - нет
+	low, high := 0, 1
+	for {
+		if found, err = exists(high); err != nil {
+			return 0, err
+		}
+		if !found {
+			break
+		}
+		low, high = high, high*2
+	}
+
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		if found, err = exists(mid); err != nil {
+			return 0, err
+		}
+		if found {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	return high, nil
+}
@@ -0,0 +1,157 @@
+package search
+
+import (
+	"context"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+	"testing"
+)
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		name  string
+		rng   Range
+		value uint
+		want  bool
+	}{
+		{name: "unset", rng: Range{}, value: 42, want: true},
+		{name: "inside", rng: Range{From: uintPtr(10), To: uintPtr(20)}, value: 15, want: true},
+		{name: "lower bound", rng: Range{From: uintPtr(10), To: uintPtr(20)}, value: 10, want: true},
+		{name: "upper bound", rng: Range{From: uintPtr(10), To: uintPtr(20)}, value: 20, want: true},
+		{name: "below", rng: Range{From: uintPtr(10), To: uintPtr(20)}, value: 9, want: false},
+		{name: "above", rng: Range{From: uintPtr(10), To: uintPtr(20)}, value: 21, want: false},
+		{name: "open end", rng: Range{From: uintPtr(10)}, value: 1000, want: true},
+		{name: "open start", rng: Range{To: uintPtr(12)}, value: 0, want: true},
+		{name: "exact zero", rng: Range{From: uintPtr(0), To: uintPtr(0)}, value: 6, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rng.Contains(tt.value); got != tt.want {
+			t.Errorf("%s: Contains(%d) = %v, want %v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQueryMatches(t *testing.T) {
+	book := &jsonmodels.BookModel{Genre: "Novel", Language: "ru", PublishingYear: 1869, AgeLimit: 12}
+
+	tests := []struct {
+		name string
+		q    Query
+		want bool
+	}{
+		{name: "no filters", q: Query{}, want: true},
+		{name: "one of genres, case-insensitive", q: Query{Genres: []string{"poetry", "novel"}}, want: true},
+		{name: "none of genres", q: Query{Genres: []string{"poetry", "drama"}}, want: false},
+		{name: "one of languages", q: Query{Languages: []string{"en", "RU"}}, want: true},
+		{name: "year range", q: Query{Year: Range{From: uintPtr(1850), To: uintPtr(1900)}}, want: true},
+		{name: "year out of range", q: Query{Year: Range{From: uintPtr(1900)}}, want: false},
+		{name: "age limit range", q: Query{AgeLimit: Range{To: uintPtr(12)}}, want: true},
+		{name: "age limit out of range", q: Query{AgeLimit: Range{To: uintPtr(6)}}, want: false},
+		{
+			name: "all filters must match",
+			q:    Query{Genres: []string{"novel"}, Languages: []string{"en"}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.Matches(book); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// catalog - каталог из n книг, выдаваемый страницами, как сервер: за
+// последней книгой сервер отвечает 404
+type catalog struct {
+	books []*jsonmodels.BookModel
+}
+
+func newCatalog(n int) *catalog {
+	c := &catalog{}
+	for i := 0; i < n; i++ {
+		genre := "novel"
+		if i%3 == 0 {
+			genre = "poetry"
+		}
+		c.books = append(c.books, &jsonmodels.BookModel{Genre: genre, PublishingYear: uint(1800 + i)})
+	}
+	return c
+}
+
+func (c *catalog) list(_ context.Context, params dto.BookParamsDTO) ([]*jsonmodels.BookModel, error) {
+	var matched []*jsonmodels.BookModel
+	for _, book := range c.books {
+		if params.Genre == "" || params.Genre == book.Genre {
+			matched = append(matched, book)
+		}
+	}
+
+	if params.Offset >= len(matched) {
+		return nil, &client.APIError{StatusCode: http.StatusNotFound}
+	}
+
+	return matched[params.Offset:min(params.Offset+int(params.Limit), len(matched))], nil
+}
+
+func TestPageAndCount(t *testing.T) {
+	poetry := Query{Genres: []string{"poetry", "drama"}}
+	var novels Query
+	novels.Params.Genre = "novel"
+
+	tests := []struct {
+		name      string
+		size      int
+		q         Query
+		offset    int
+		limit     uint
+		wantYears []uint
+		wantCount int
+	}{
+		{name: "server-side page", size: 10, q: novels, offset: 2, limit: 3, wantYears: []uint{1804, 1805, 1807}, wantCount: 6},
+		{name: "client-side page", size: 10, q: poetry, offset: 1, limit: 2, wantYears: []uint{1803, 1806}, wantCount: 4},
+		{name: "client-side page across batches", size: 160, q: poetry, offset: 20, limit: 2, wantYears: []uint{1860, 1863}, wantCount: 54},
+		{name: "page past the end", size: 10, q: poetry, offset: 10, limit: 5, wantCount: 4},
+		{name: "empty catalog", size: 0, q: Query{}, limit: 5, wantCount: 0},
+		{name: "exactly one batch", size: scanBatch, q: Query{Year: Range{From: uintPtr(1800)}}, offset: scanBatch - 1, limit: 5, wantYears: []uint{1849}, wantCount: scanBatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCatalog(tt.size)
+
+			page, err := Page(context.Background(), c.list, tt.q, tt.offset, tt.limit)
+			if err != nil && !client.IsNotFound(err) {
+				t.Fatalf("Page: %v", err)
+			}
+
+			var years []uint
+			for _, book := range page {
+				years = append(years, book.PublishingYear)
+			}
+			if len(years) != len(tt.wantYears) {
+				t.Fatalf("Page years = %v, want %v", years, tt.wantYears)
+			}
+			for i := range years {
+				if years[i] != tt.wantYears[i] {
+					t.Fatalf("Page years = %v, want %v", years, tt.wantYears)
+				}
+			}
+
+			count, err := Count(context.Background(), c.list, tt.q)
+			if err != nil {
+				t.Fatalf("Count: %v", err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("Count = %d, want %d", count, tt.wantCount)
+			}
+		})
+	}
+}
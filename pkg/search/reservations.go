@@ -0,0 +1,142 @@
+package search
+
+import (
+	"github.com/google/uuid"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Поля, по которым сортируются брони
+const (
+	SortByIssueDate  = "issue"
+	SortByReturnDate = "return"
+)
+
+// ReservationQuery - фильтры истории броней. Сервер отдает все брони читателя
+// целиком, поэтому фильтрация и сортировка выполняются на клиенте
+type ReservationQuery struct {
+	States []string `json:"states,omitempty"`
+	// From и To ограничивают период брони: попадают брони, которые
+	// действовали хотя бы один день диапазона
+	From   *time.Time `json:"from,omitempty"`
+	To     *time.Time `json:"to,omitempty"`
+	SortBy string     `json:"sort_by,omitempty"`
+	Desc   bool       `json:"desc,omitempty"`
+
+	// Book - начало ID книги или часть ее названия, Reader - начало ID
+	// читателя; Overdue отбирает просроченные (true) или остальные (false)
+	Book    string `json:"book,omitempty"`
+	Reader  string `json:"reader,omitempty"`
+	Overdue *bool  `json:"overdue,omitempty"`
+
+	// BookTitle возвращает название книги для фильтра Book; nil - книга
+	// ищется только по ID
+	BookTitle func(bookID uuid.UUID) string `json:"-"`
+}
+
+// Overdue сообщает, что книга по брони не возвращена в срок: сервер уже
+// перевел бронь в Expired или дата возврата прошла
+func Overdue(reservation *jsonmodels.ReservationModel, now time.Time) bool {
+	if reservation.State == "Closed" {
+		return false
+	}
+
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+
+	return reservation.State == "Expired" || reservation.ReturnDate.Before(today)
+}
+
+// Matches проверяет бронь по всем заданным фильтрам
+func (q ReservationQuery) Matches(reservation *jsonmodels.ReservationModel) bool {
+	return oneOf(reservation.State, q.States) &&
+		(q.From == nil || !reservation.ReturnDate.Before(*q.From)) &&
+		(q.To == nil || reservation.IssueDate.Before(q.To.AddDate(0, 0, 1))) &&
+		q.matchesBook(reservation.BookID) &&
+		hasIDPrefix(reservation.ReaderID, q.Reader) &&
+		(q.Overdue == nil || Overdue(reservation, time.Now()) == *q.Overdue)
+}
+
+func (q ReservationQuery) matchesBook(bookID uuid.UUID) bool {
+	if q.Book == "" || hasIDPrefix(bookID, q.Book) {
+		return true
+	}
+
+	return q.BookTitle != nil &&
+		strings.Contains(strings.ToLower(q.BookTitle(bookID)), strings.ToLower(q.Book))
+}
+
+func hasIDPrefix(id uuid.UUID, prefix string) bool {
+	return strings.HasPrefix(id.String(), strings.ToLower(prefix))
+}
+
+// Apply возвращает подходящие брони в заданном порядке; исходный срез не меняется
+func (q ReservationQuery) Apply(reservations []*jsonmodels.ReservationModel) []*jsonmodels.ReservationModel {
+	res := make([]*jsonmodels.ReservationModel, 0, len(reservations))
+	for _, reservation := range reservations {
+		if q.Matches(reservation) {
+			res = append(res, reservation)
+		}
+	}
+
+	date := func(reservation *jsonmodels.ReservationModel) time.Time {
+		if q.SortBy == SortByIssueDate {
+			return reservation.IssueDate
+		}
+		return reservation.ReturnDate
+	}
+
+	if q.SortBy != "" {
+		slices.SortStableFunc(res, func(a, b *jsonmodels.ReservationModel) int {
+			if q.Desc {
+				return date(b).Compare(date(a))
+			}
+			return date(a).Compare(date(b))
+		})
+	}
+
+	return res
+}
+
+// IsSet сообщает, что задан хотя бы один фильтр или сортировка
+func (q ReservationQuery) IsSet() bool {
+	return len(q.States) > 0 || q.From != nil || q.To != nil || q.SortBy != "" ||
+		q.Book != "" || q.Reader != "" || q.Overdue != nil
+}
+
+// String описывает фильтры для заголовка таблицы
+func (q ReservationQuery) String() string {
+	var parts []string
+	if len(q.States) > 0 {
+		parts = append(parts, "state: "+strings.Join(q.States, ", "))
+	}
+	if q.From != nil {
+		parts = append(parts, "from "+q.From.Format(time.DateOnly))
+	}
+	if q.To != nil {
+		parts = append(parts, "to "+q.To.Format(time.DateOnly))
+	}
+	if q.Book != "" {
+		parts = append(parts, "book: "+q.Book)
+	}
+	if q.Reader != "" {
+		parts = append(parts, "reader: "+q.Reader)
+	}
+	if q.Overdue != nil && *q.Overdue {
+		parts = append(parts, "overdue")
+	}
+	if q.Overdue != nil && !*q.Overdue {
+		parts = append(parts, "not overdue")
+	}
+	if q.SortBy != "" {
+		order := "ascending"
+		if q.Desc {
+			order = "descending"
+		}
+		parts = append(parts, "sorted by "+q.SortBy+" date, "+order)
+	}
+
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,156 @@
+package search
+
+import (
+	"github.com/google/uuid"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func datePtr(s string) *time.Time {
+	d := date(s)
+	return &d
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+var (
+	warAndPeace = uuid.MustParse("aaaa0000-0000-4000-8000-000000000001")
+	karenina    = uuid.MustParse("bbbb0000-0000-4000-8000-000000000002")
+	reader1     = uuid.MustParse("cccc1111-0000-4000-8000-000000000003")
+	reader2     = uuid.MustParse("dddd2222-0000-4000-8000-000000000004")
+)
+
+// testReservations - брони с разными состояниями и сроками; номер брони
+// хранится в первом байте ID
+func testReservations() []*jsonmodels.ReservationModel {
+	now := time.Now()
+	reservation := func(num byte, state string, bookID, readerID uuid.UUID, issue, ret time.Time) *jsonmodels.ReservationModel {
+		return &jsonmodels.ReservationModel{
+			ID:         uuid.UUID{num},
+			ReaderID:   readerID,
+			BookID:     bookID,
+			IssueDate:  issue,
+			ReturnDate: ret,
+			State:      state,
+		}
+	}
+
+	return []*jsonmodels.ReservationModel{
+		reservation(1, "Closed", warAndPeace, reader1, date("2024-08-01"), date("2024-08-15")),
+		reservation(2, "Issued", karenina, reader1, date("2024-09-10"), date("2024-09-24")),
+		reservation(3, "Extended", warAndPeace, reader2, date("2024-09-01"), date("2024-09-22")),
+		reservation(4, "Expired", karenina, reader2, date("2024-07-01"), date("2024-07-15")),
+		reservation(5, "Issued", warAndPeace, reader2, now.AddDate(0, 0, -3), now.AddDate(0, 0, 11)),
+	}
+}
+
+func nums(reservations []*jsonmodels.ReservationModel) []byte {
+	res := make([]byte, len(reservations))
+	for i, reservation := range reservations {
+		res[i] = reservation.ID[0]
+	}
+	return res
+}
+
+func TestReservationQueryApply(t *testing.T) {
+	titles := map[uuid.UUID]string{warAndPeace: "War and Peace", karenina: "Anna Karenina"}
+
+	tests := []struct {
+		name string
+		q    ReservationQuery
+		want []byte
+	}{
+		{name: "no filters keeps order", q: ReservationQuery{}, want: []byte{1, 2, 3, 4, 5}},
+		{name: "one state", q: ReservationQuery{States: []string{"Issued"}}, want: []byte{2, 5}},
+		{name: "several states, any case", q: ReservationQuery{States: []string{"closed", "EXPIRED"}}, want: []byte{1, 4}},
+		{
+			name: "period overlaps the range",
+			q:    ReservationQuery{From: datePtr("2024-09-01"), To: datePtr("2024-09-30")},
+			want: []byte{2, 3},
+		},
+		{name: "return on the From day counts", q: ReservationQuery{From: datePtr("2024-08-15"), To: datePtr("2024-08-20")}, want: []byte{1}},
+		{name: "issue on the To day counts", q: ReservationQuery{From: datePtr("2024-09-05"), To: datePtr("2024-09-10")}, want: []byte{2, 3}},
+		{name: "only To", q: ReservationQuery{To: datePtr("2024-07-31")}, want: []byte{4}},
+		{name: "book by ID prefix", q: ReservationQuery{Book: "BBBB"}, want: []byte{2, 4}},
+		{name: "book by title without titles", q: ReservationQuery{Book: "karenina"}, want: []byte{}},
+		{name: "book by title", q: ReservationQuery{Book: "karenina", BookTitle: func(id uuid.UUID) string { return titles[id] }}, want: []byte{2, 4}},
+		{name: "reader by ID prefix", q: ReservationQuery{Reader: "dddd"}, want: []byte{3, 4, 5}},
+		{name: "overdue", q: ReservationQuery{Overdue: boolPtr(true)}, want: []byte{2, 3, 4}},
+		{name: "not overdue", q: ReservationQuery{Overdue: boolPtr(false)}, want: []byte{1, 5}},
+		{name: "sort by return date", q: ReservationQuery{SortBy: SortByReturnDate}, want: []byte{4, 1, 3, 2, 5}},
+		{name: "sort by issue date descending", q: ReservationQuery{SortBy: SortByIssueDate, Desc: true}, want: []byte{5, 2, 3, 1, 4}},
+		{
+			name: "filters and sort together",
+			q:    ReservationQuery{States: []string{"Issued", "Extended"}, Reader: "dddd", SortBy: SortByReturnDate, Desc: true},
+			want: []byte{5, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reservations := testReservations()
+
+			got := nums(tt.q.Apply(reservations))
+			if string(got) != string(tt.want) {
+				t.Errorf("Apply = %v, want %v", got, tt.want)
+			}
+
+			if order := nums(reservations); string(order) != string([]byte{1, 2, 3, 4, 5}) {
+				t.Errorf("Apply changed the source slice: %v", order)
+			}
+		})
+	}
+}
+
+func TestOverdue(t *testing.T) {
+	now := date("2024-09-20").Add(15 * time.Hour)
+
+	tests := []struct {
+		state  string
+		ret    time.Time
+		expect bool
+	}{
+		{state: "Issued", ret: date("2024-09-19"), expect: true},
+		{state: "Issued", ret: date("2024-09-20"), expect: false},
+		{state: "Extended", ret: date("2024-09-25"), expect: false},
+		{state: "Expired", ret: date("2024-09-25"), expect: true},
+		{state: "Closed", ret: date("2024-09-01"), expect: false},
+	}
+
+	for _, tt := range tests {
+		reservation := &jsonmodels.ReservationModel{State: tt.state, ReturnDate: tt.ret}
+		if got := Overdue(reservation, now); got != tt.expect {
+			t.Errorf("Overdue(%s, return %s) = %v, want %v", tt.state, tt.ret.Format(time.DateOnly), got, tt.expect)
+		}
+	}
+}
+
+func TestReservationQueryString(t *testing.T) {
+	q := ReservationQuery{
+		States:  []string{"Issued", "Extended"},
+		From:    datePtr("2024-09-01"),
+		Overdue: boolPtr(true),
+		SortBy:  SortByReturnDate,
+		Desc:    true,
+	}
+
+	want := "state: Issued, Extended; from 2024-09-01; overdue; sorted by return date, descending"
+	if got := q.String(); got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+	if !q.IsSet() || (ReservationQuery{}).IsSet() || strings.TrimSpace((ReservationQuery{}).String()) != "" {
+		t.Error("IsSet should report only queries with filters")
+	}
+}
@@ -0,0 +1,224 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"golang.org/x/crypto/scrypt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	RoleReader = "reader"
+	RoleAdmin  = "admin"
+)
+
+var (
+	ErrNoSession       = errors.New("no saved session")
+	ErrWrongPassphrase = errors.New("cannot decrypt saved session: wrong passphrase or corrupted file")
+)
+
+// Session - сохраняемое между запусками состояние входа
+type Session struct {
+	Tokens   dto.ReaderTokensDTO `json:"tokens"`
+	IssuedAt time.Time           `json:"issued_at"`
+	Role     string              `json:"role"`
+}
+
+// ISessionStore - хранилище сессии
+type ISessionStore interface {
+	Save(s Session) error
+	Load() (Session, error)
+	Delete() error
+}
+
+// FileStore хранит сессию в файле с правами 0600, зашифрованной AES-GCM
+// ключом, выведенным из парольной фразы через scrypt
+type FileStore struct {
+	path       string
+	passphrase []byte
+}
+
+// NewFileStore создает хранилище сессии в файле path
+func NewFileStore(path, passphrase string) *FileStore {
+	return &FileStore{
+		path:       path,
+		passphrase: []byte(passphrase),
+	}
+}
+
+// sealedFile - формат файла сессии
+type sealedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+const (
+	fileVersion = 1
+	saltSize    = 16
+	keySize     = 32
+)
+
+// Save шифрует и атомарно записывает сессию
+func (fs *FileStore) Save(s Session) error {
+	plain, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return err
+	}
+
+	gcm, err := fs.cipher(salt)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	sealed, err := json.Marshal(sealedFile{
+		Version: fileVersion,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fs.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), ".session-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	// CreateTemp создает файл с правами 0600, но явно выставляем их на случай umask
+	if err = tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err = tmp.Write(sealed); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fs.path)
+}
+
+// Load читает и расшифровывает сессию; ErrNoSession, если сессия не сохранена
+func (fs *FileStore) Load() (Session, error) {
+	data, err := os.ReadFile(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Session{}, ErrNoSession
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	var sealed sealedFile
+	if err = json.Unmarshal(data, &sealed); err != nil || sealed.Version != fileVersion {
+		return Session{}, ErrWrongPassphrase
+	}
+
+	gcm, err := fs.cipher(sealed.Salt)
+	if err != nil {
+		return Session{}, err
+	}
+	if len(sealed.Nonce) != gcm.NonceSize() {
+		return Session{}, ErrWrongPassphrase
+	}
+
+	plain, err := gcm.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return Session{}, ErrWrongPassphrase
+	}
+
+	var s Session
+	if err = json.Unmarshal(plain, &s); err != nil {
+		return Session{}, fmt.Errorf("decoding saved session: %w", err)
+	}
+
+	return s, nil
+}
+
+// Delete удаляет сохраненную сессию
+func (fs *FileStore) Delete() error {
+	err := os.Remove(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func (fs *FileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(fs.passphrase, salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// MemoryStore хранит сессию только в памяти, пока работает программа
+type MemoryStore struct {
+	mu      sync.Mutex
+	session *Session
+}
+
+// NewMemoryStore создает пустое хранилище сессии в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Save запоминает сессию
+func (ms *MemoryStore) Save(s Session) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.session = &s
+	return nil
+}
+
+// Load возвращает сессию; ErrNoSession, если сессия не сохранена
+func (ms *MemoryStore) Load() (Session, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.session == nil {
+		return Session{}, ErrNoSession
+	}
+	return *ms.session, nil
+}
+
+// Delete забывает сессию
+func (ms *MemoryStore) Delete() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.session = nil
+	return nil
+}
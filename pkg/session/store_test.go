@@ -0,0 +1,188 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func testSession() Session {
+	return Session{
+		Tokens: dto.ReaderTokensDTO{
+			AccessToken:  "access",
+			RefreshToken: "refresh",
+			ExpiredAt:    1700000000000,
+		},
+		IssuedAt: time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC),
+		Role:     RoleAdmin,
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "booksmart", "session.json")
+	store := NewFileStore(path, "passphrase")
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("Load before Save: got %v, want %v", err, ErrNoSession)
+	}
+
+	want := testSession()
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := NewFileStore(path, "passphrase").Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.Tokens != want.Tokens || !got.IssuedAt.Equal(want.IssuedAt) || got.Role != want.Role {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+
+	if err = store.Delete(); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err = store.Load(); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Load after Delete: got %v, want %v", err, ErrNoSession)
+	}
+	if err = store.Delete(); err != nil {
+		t.Errorf("Delete of a missing session: %v", err)
+	}
+}
+
+func TestFileStoreRejectsBadFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		corrupt    func(t *testing.T, path string)
+		passphrase string
+	}{
+		{
+			name:       "wrong passphrase",
+			corrupt:    func(t *testing.T, path string) {},
+			passphrase: "another passphrase",
+		},
+		{
+			name: "tampered ciphertext",
+			corrupt: func(t *testing.T, path string) {
+				editSealed(t, path, func(sealed *sealedFile) { sealed.Data[0] ^= 0xff })
+			},
+		},
+		{
+			name: "tampered nonce",
+			corrupt: func(t *testing.T, path string) {
+				editSealed(t, path, func(sealed *sealedFile) { sealed.Nonce = sealed.Nonce[1:] })
+			},
+		},
+		{
+			name: "unknown version",
+			corrupt: func(t *testing.T, path string) {
+				editSealed(t, path, func(sealed *sealedFile) { sealed.Version++ })
+			},
+		},
+		{
+			name: "not json",
+			corrupt: func(t *testing.T, path string) {
+				if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.json")
+			if err := NewFileStore(path, "passphrase").Save(testSession()); err != nil {
+				t.Fatal(err)
+			}
+			tt.corrupt(t, path)
+
+			passphrase := "passphrase"
+			if tt.passphrase != "" {
+				passphrase = tt.passphrase
+			}
+
+			if _, err := NewFileStore(path, passphrase).Load(); !errors.Is(err, ErrWrongPassphrase) {
+				t.Errorf("Load: got %v, want %v", err, ErrWrongPassphrase)
+			}
+		})
+	}
+}
+
+func TestFileStoreFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := NewFileStore(path, "passphrase").Save(testSession()); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("session file mode = %o, want 600", mode)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("files next to the session: %d, want only the session itself", len(entries))
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+
+	if _, err := store.Load(); !errors.Is(err, ErrNoSession) {
+		t.Fatalf("Load before Save: got %v, want %v", err, ErrNoSession)
+	}
+
+	want := testSession()
+	if err := store.Save(want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Load(); err != nil || got.Tokens != want.Tokens {
+		t.Errorf("Load = %+v, %v; want %+v", got, err, want)
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(); !errors.Is(err, ErrNoSession) {
+		t.Errorf("Load after Delete: got %v, want %v", err, ErrNoSession)
+	}
+}
+
+// editSealed меняет сохраненный файл сессии, не расшифровывая его
+func editSealed(t *testing.T, path string, edit func(sealed *sealedFile)) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sealed sealedFile
+	if err = json.Unmarshal(data, &sealed); err != nil {
+		t.Fatal(err)
+	}
+	edit(&sealed)
+
+	if data, err = json.Marshal(sealed); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
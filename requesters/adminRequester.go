@@ -1,29 +1,50 @@
 package requesters
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
-	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
 	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
-	"net/http"
 	"time"
 )
 
-func (r *Requester) ProcessAdminActions() error {
-
-	stopRefresh := make(chan struct{})
+const adminMainMenu = `Main menu:
+	1 -- go to books catalog
+	2 -- go to library card
+	3 -- go to your reservations
+	4 -- go to your favorites
+	5 -- extend now (due reservations)
+	6 -- reservations dashboard
+	0 -- log out
+`
 
-	if err := r.SignInAsAdmin(stopRefresh); err != nil {
+func (r *Requester) ProcessAdminActions(ctx context.Context) error {
+	if err := interruptible(ctx, r.SignInAsAdmin); err != nil {
 		return err
 	}
 
+	return r.adminSession(ctx)
+}
+
+// adminSession - меню администратора, в которое он попадает после входа
+func (r *Requester) adminSession(ctx context.Context) error {
+	r.cache.Clear()
+	r.remindedAt = time.Time{}
+
+	sessionCtx, stopRefresh := context.WithCancel(ctx)
+	defer stopRefresh()
+
+	go r.Refreshing(sessionCtx)
+
 	for {
-		fmt.Printf("\n\n%s", readerMainMenu)
+		r.remindIfDue(ctx)
+		fmt.Printf("\n\n%s\n%s", r.sessionInfo(), adminMainMenu)
 
-		menuItem, err := input.MenuItem()
+		menuItem, err := r.in.MenuItem()
 		if err != nil {
 			fmt.Println(err)
 			continue
@@ -31,70 +52,65 @@ func (r *Requester) ProcessAdminActions() error {
 
 		switch menuItem {
 		case 1:
-			err = r.ProcessAdminBookCatalogActions()
+			err = r.ProcessAdminBookCatalogActions(ctx)
 			if err != nil {
 				fmt.Println(err)
 			}
 		case 2:
-			err = r.ProcessLibCardActions()
+			err = r.ProcessLibCardActions(ctx)
 			if err != nil {
 				fmt.Println(err)
 			}
 		case 3:
-			err = r.ProcessReservationsActions()
+			err = r.ProcessReservationsActions(ctx)
+			if err != nil {
+				fmt.Println(err)
+			}
+		case 4:
+			err = r.ProcessFavoritesActions(ctx)
+			if err != nil {
+				fmt.Println(err)
+			}
+		case 5:
+			err = interruptible(ctx, r.extendNow)
+			if err != nil {
+				fmt.Println(err)
+			}
+		case 6:
+			err = r.ProcessDashboardActions(ctx)
 			if err != nil {
 				fmt.Println(err)
 			}
 		case 0:
-			close(stopRefresh)
+			stopRefresh()
+			r.logOut()
 			fmt.Println("\n\nyou have successfully log out")
 			return nil
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
-	}
-}
-
-func (r *Requester) SignInAsAdmin(stopRefresh <-chan struct{}) error {
-	readerSignInDTO, err := input.SignInParams()
-	if err != nil {
-		return err
-	}
 
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/auth/admin/sign-in",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		Body:    readerSignInDTO,
-		Timeout: 10 * time.Second,
+		// refresh-токен отклонен сервером - возвращаемся к входу
+		if !r.client.IsAuthenticated() {
+			stopRefresh()
+			return nil
+		}
 	}
+}
 
-	response, err := SendRequest(request)
+func (r *Requester) SignInAsAdmin(ctx context.Context) error {
+	readerSignInDTO, err := r.in.SignInParams()
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
-	var tokens dto.ReaderTokensDTO
-	if err = json.Unmarshal(response.Body, &tokens); err != nil {
+	r.setRole(session.RoleAdmin)
+	if _, err = r.client.SignInAsAdmin(ctx, readerSignInDTO); err != nil {
 		return err
 	}
 
-	r.cache.Set(tokensKey, tokens)
-
 	fmt.Printf("\n\nAuthentication successful!\n")
 
-	go r.Refreshing(r.accessTokenTTL, stopRefresh)
-
 	return nil
 }
 
@@ -102,21 +118,27 @@ const adminCatalogMenu = `Admin's Catalog menu:
 	1 -- view books
 	2 -- next page
 	3 -- view info about book
-	4 -- add book to favorites
-	5 -- reserve book
+	4 -- add books to favorites
+	5 -- reserve books
 	6 -- add new book
-	7 -- delete book
+	7 -- delete books
+	8 -- previous page
+	9 -- go to page
+	10 -- first page
+	11 -- last page
+	12 -- change page size
 	0 -- go to main menu
 `
 
-func (r *Requester) ProcessAdminBookCatalogActions() error {
-	r.cache.Set(bookParamsKey, dto.BookParamsDTO{Limit: pageLimit, Offset: 0})
+func (r *Requester) ProcessAdminBookCatalogActions(ctx context.Context) error {
+	r.cache.Set(bookParamsKey, newBookSearch(search.Query{}))
 	r.cache.Set(booksKey, make([]uuid.UUID, 0))
+	r.cache.Set(bookModelsKey, make(map[uuid.UUID]*jsonmodels.BookModel))
 
 	for {
 		fmt.Printf("\n\n%s", adminCatalogMenu)
 
-		menuItem, err := input.MenuItem()
+		menuItem, err := r.in.MenuItem()
 		if err != nil {
 			fmt.Println(err)
 			continue
@@ -124,31 +146,51 @@ func (r *Requester) ProcessAdminBookCatalogActions() error {
 
 		switch menuItem {
 		case 1:
-			if err = r.viewFirstPage(); err != nil {
+			if err = interruptible(ctx, r.viewFirstPage); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 2:
-			if err = r.viewNextPage(); err != nil {
+			if err = interruptible(ctx, r.viewNextPage); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 3:
-			if err = r.ViewBook(); err != nil {
+			if err = interruptible(ctx, r.ViewBook); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 4:
-			if err = r.AddToFavorites(); err != nil {
+			if err = interruptible(ctx, r.AddToFavorites); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 5:
-			if err = r.ReserveBook(); err != nil {
+			if err = interruptible(ctx, r.ReserveBook); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 6:
-			if err = r.AddNewBook(); err != nil {
+			if err = interruptible(ctx, r.AddNewBook); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 7:
-			if err = r.DeleteBook(); err != nil {
+			if err = interruptible(ctx, r.DeleteBook); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 8:
+			if err = interruptible(ctx, r.viewPrevPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 9:
+			if err = interruptible(ctx, r.jumpToPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 10:
+			if err = interruptible(ctx, r.viewStartPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 11:
+			if err = interruptible(ctx, r.viewLastPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 12:
+			if err = interruptible(ctx, r.changePageSize); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 0:
@@ -156,96 +198,60 @@ func (r *Requester) ProcessAdminBookCatalogActions() error {
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
-	}
-}
 
-func (r *Requester) AddNewBook() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
+		// сессия закончилась - выходим, меню читателя вернет на вход
+		if errors.Is(err, client.ErrSessionExpired) {
+			return nil
+		}
 	}
+}
 
-	newBook, err := input.Book()
+func (r *Requester) AddNewBook(ctx context.Context) error {
+	newBook, err := r.in.Book()
 	if err != nil {
 		return err
 	}
 
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/api/admin/books",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Body:    newBook,
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
+	if err = r.client.AddBook(ctx, newBook); err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusCreated {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nBook successfully created!\n")
 
 	return nil
 }
 
-func (r *Requester) DeleteBook() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
+// DeleteBook удаляет одну или несколько книг после подтверждения
+func (r *Requester) DeleteBook(ctx context.Context) error {
+	selected, err := r.selectBooks()
+	if err != nil {
 		return err
 	}
 
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
+	if len(selected) > 1 {
+		return r.deleteBooks(ctx, selected)
 	}
 
-	num, err := input.BookPagesNumber()
+	book, err := r.client.GetBook(ctx, selected[0].id)
 	if err != nil {
 		return err
 	}
 
-	if num > len(bookPagesID) || num < 0 {
-		return errors.New("book number out of range")
-	}
-
-	bookID := bookPagesID[num]
-
-	if err = r.getReservationsByBook(bookID); err != nil {
+	if err = r.getReservationsByBook(ctx, book.ID); err != nil {
 		return err
 	}
 
-	request := HTTPRequest{
-		Method: http.MethodDelete,
-		URL:    r.baseURL + fmt.Sprintf("/api/admin/books/%s", bookID.String()),
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
+	confirmed, err := r.in.Confirm(fmt.Sprintf("Delete %q by %s?", book.Title, book.Author))
 	if err != nil {
 		return err
 	}
+	if !confirmed {
+		fmt.Printf("\n\nBook was not deleted\n")
+		return nil
+	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
+	if err = r.client.DeleteBook(ctx, book.ID); err != nil {
+		return err
 	}
 
 	fmt.Printf("\n\nBook successfully deleted!\n")
@@ -253,46 +259,38 @@ func (r *Requester) DeleteBook() error {
 	return nil
 }
 
-func (r *Requester) getReservationsByBook(bookID uuid.UUID) error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
+func (r *Requester) deleteBooks(ctx context.Context, selected []selection) error {
+	fmt.Printf("\n\nSelected books:\n")
+	for _, s := range selected {
+		fmt.Printf("\t%s\n", r.bookTitle(s.id))
 	}
 
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/api/admin/reservations",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		QueryParams: map[string]string{
-			"book_id": bookID.String(),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
+	confirmed, err := r.in.Confirm(fmt.Sprintf("Delete %d books?", len(selected)))
 	if err != nil {
 		return err
 	}
-
-	if response.StatusCode == http.StatusNotFound {
+	if !confirmed {
+		fmt.Printf("\n\nBooks were not deleted\n")
 		return nil
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
+	return r.forEachBook(ctx, "Deletion", selected, func(ctx context.Context, bookID uuid.UUID) error {
+		if err := r.getReservationsByBook(ctx, bookID); err != nil {
 			return err
 		}
-		return errors.New(info)
-	}
+		return r.client.DeleteBook(ctx, bookID)
+	})
+}
 
-	var reservations []*jsonmodels.ReservationModel
-	if err = json.Unmarshal(response.Body, &reservations); err != nil {
+func (r *Requester) getReservationsByBook(ctx context.Context, bookID uuid.UUID) error {
+	reservations, err := r.client.ListReservationsByBook(ctx, bookID)
+	if client.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
+
 	if len(reservations) > 0 {
 		return errors.New("this book cannot be deleted, it is reserved")
 	}
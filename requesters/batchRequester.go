@@ -0,0 +1,326 @@
+package requesters
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Сценарий - текстовый файл, по команде в строке. Кроме обычных команд
+// доступны sign-in и logout:
+//
+//	# комментарий
+//	sign-in --phone 89999999999 --password ${env.READER_PASSWORD}
+//	book = books list --author Tolstoy --limit 1
+//	books reserve ${book.0.id}
+//	logout
+//
+// "имя = команда" сохраняет результат команды (то, что она вывела, в виде JSON),
+// а ${имя.путь} подставляет поле результата; элементы списков выбираются по номеру
+const scriptUsage = `usage: run [--stop-on-error] <script|->`
+
+var (
+	varRegexp  = regexp.MustCompile(`\$\{([^}]+)\}`)
+	nameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// passwordRegexp находит значение флага --password (-password, --password=)
+	passwordRegexp = regexp.MustCompile(`(\s--?password)(=|\s+)("[^"]*"|'[^']*'|\S+)`)
+)
+
+// scriptStep - результат одного шага сценария
+type scriptStep struct {
+	line     int
+	command  string
+	status   string
+	code     int
+	err      error
+	duration time.Duration
+}
+
+const (
+	stepOK      = "ok"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+)
+
+func (r *Requester) cmdRun(ctx context.Context, args []string) error {
+	fs := newFlagSet("run")
+	stopOnError := fs.Bool("stop-on-error", false, "stop at the first failed command")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError{msg: scriptUsage}
+	}
+
+	var script io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = file.Close() }()
+		script = file
+	}
+
+	steps, err := r.runScript(ctx, script, *stopOnError)
+	printScriptSummary(steps)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if step.err != nil {
+			return fmt.Errorf("script failed at line %d: %w", step.line, step.err)
+		}
+	}
+
+	return nil
+}
+
+// runScript выполняет команды сценария по порядку и возвращает отчет по шагам
+func (r *Requester) runScript(ctx context.Context, script io.Reader, stopOnError bool) ([]scriptStep, error) {
+	var (
+		steps   []scriptStep
+		vars    = make(map[string]interface{})
+		stopped bool
+	)
+
+	scanner := bufio.NewScanner(script)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// пароль не должен попасть ни в stderr, ни в отчет
+		step := scriptStep{line: lineNum, command: maskSecrets(line)}
+		if stopped || ctx.Err() != nil {
+			step.status = stepSkipped
+			steps = append(steps, step)
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "==> [%d] %s\n", lineNum, step.command)
+
+		start := time.Now()
+		step.err = r.runScriptLine(ctx, line, vars)
+		step.duration = time.Since(start)
+		step.code = ExitCode(step.err)
+
+		step.status = stepOK
+		if step.err != nil {
+			step.status = stepFailed
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", lineNum, step.err)
+			stopped = stopOnError
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, scanner.Err()
+}
+
+func (r *Requester) runScriptLine(ctx context.Context, line string, vars map[string]interface{}) error {
+	args, err := input.SplitArgs(line)
+	if err != nil {
+		return usageError{msg: err.Error()}
+	}
+	if len(args) == 0 {
+		return usageError{msg: "empty command"}
+	}
+
+	// имя = команда
+	capture := ""
+	if len(args) > 2 && args[1] == "=" {
+		if !nameRegexp.MatchString(args[0]) {
+			return usageError{msg: fmt.Sprintf("invalid variable name %q", args[0])}
+		}
+		capture, args = args[0], args[2:]
+	}
+
+	for i := range args {
+		if args[i], err = substitute(args[i], vars); err != nil {
+			return usageError{msg: err.Error()}
+		}
+	}
+
+	r.lastResult = nil
+
+	switch args[0] {
+	case "sign-in":
+		err = r.scriptSignIn(ctx, args[1:])
+	case "logout":
+		r.logOut()
+	case "run":
+		err = usageError{msg: "run cannot be nested"}
+	default:
+		cmd, rest, ok := r.findCommand(args)
+		if !ok {
+			return usageError{msg: fmt.Sprintf("unknown command %q", strings.Join(args, " "))}
+		}
+		err = r.execCommand(ctx, cmd, rest)
+	}
+	if err != nil {
+		return err
+	}
+
+	if capture != "" {
+		if vars[capture], err = toGeneric(r.lastResult); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maskSecrets заменяет значение --password в строке сценария звездочками
+func maskSecrets(line string) string {
+	return passwordRegexp.ReplaceAllString(line, "$1$2***")
+}
+
+// scriptSignIn - шаг sign-in [--admin] --phone P --password PW
+func (r *Requester) scriptSignIn(ctx context.Context, args []string) error {
+	var (
+		signIn dto.ReaderSignInDTO
+		admin  bool
+	)
+
+	fs := newFlagSet("sign-in")
+	fs.StringVar(&signIn.PhoneNumber, "phone", "", "phone number")
+	fs.StringVar(&signIn.Password, "password", "", "password")
+	fs.BoolVar(&admin, "admin", false, "sign in as administrator")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if signIn.PhoneNumber == "" || signIn.Password == "" {
+		return usageError{msg: "usage: sign-in [--admin] --phone P --password PW"}
+	}
+
+	var err error
+	if admin {
+		r.setRole(session.RoleAdmin)
+		_, err = r.client.SignInAsAdmin(ctx, signIn)
+	} else {
+		r.setRole(session.RoleReader)
+		_, err = r.client.SignIn(ctx, signIn)
+	}
+
+	return err
+}
+
+// substitute заменяет ${имя.путь} значениями переменных; ${env.NAME} -
+// переменная окружения
+func substitute(arg string, vars map[string]interface{}) (string, error) {
+	var err error
+
+	res := varRegexp.ReplaceAllStringFunc(arg, func(ref string) string {
+		path := strings.Split(varRegexp.FindStringSubmatch(ref)[1], ".")
+
+		if path[0] == "env" && len(path) == 2 {
+			if _, ok := vars["env"]; !ok {
+				return os.Getenv(path[1])
+			}
+		}
+
+		value, ok := vars[path[0]]
+		if !ok {
+			err = fmt.Errorf("undefined variable %q", path[0])
+			return ref
+		}
+
+		for _, key := range path[1:] {
+			if value, ok = lookup(value, key); !ok {
+				err = fmt.Errorf("%s has no field %q", ref, key)
+				return ref
+			}
+		}
+
+		switch v := value.(type) {
+		case string:
+			return v
+		case nil:
+			return ""
+		default:
+			b, _ := json.Marshal(v)
+			return string(b)
+		}
+	})
+
+	return res, err
+}
+
+func lookup(value interface{}, key string) (interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		field, ok := v[key]
+		return field, ok
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, false
+		}
+		return v[i], true
+	default:
+		return nil, false
+	}
+}
+
+// toGeneric приводит результат команды к виду JSON (map, срез, строка, число)
+func toGeneric(data interface{}) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(b, &generic)
+
+	return generic, err
+}
+
+// printScriptSummary выводит отчет по шагам в stderr, чтобы не смешивать его
+// с выводом команд
+func printScriptSummary(steps []scriptStep) {
+	t := table.NewWriter()
+	t.SetTitle("Script summary")
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+	t.AppendHeader(table.Row{"Line", "Command", "Status", "Exit Code", "Duration", "Error"})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Command", WidthMax: 50},
+		{Name: "Error", WidthMax: 50},
+	})
+
+	counts := make(map[string]int)
+	for _, step := range steps {
+		var code, duration, errStr interface{} = step.code, step.duration.Round(time.Millisecond), ""
+		if step.status == stepSkipped {
+			code, duration = "-", "-"
+		}
+		if step.err != nil {
+			errStr = step.err.Error()
+		}
+		t.AppendRow(table.Row{step.line, step.command, step.status, code, duration, errStr})
+		counts[step.status]++
+	}
+	t.AppendFooter(table.Row{"", "Total", fmt.Sprintf("%d ok, %d failed, %d skipped",
+		counts[stepOK], counts[stepFailed], counts[stepSkipped])})
+
+	fmt.Fprintln(os.Stderr, t.Render())
+}
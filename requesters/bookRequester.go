@@ -1,81 +1,106 @@
 package requesters
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
 	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
-	"net/http"
-	"time"
 )
 
 const catalogMenu = `Catalog's menu:
 	1 -- view books
 	2 -- next page
 	3 -- view info about book
-	4 -- add book to favorites
-	5 -- reserve book
+	4 -- add books to favorites
+	5 -- reserve books
 	6 -- view book ratings
 	7 -- add book rating 
+	8 -- previous page
+	9 -- go to page
+	10 -- first page
+	11 -- last page
+	12 -- change page size
 	0 -- go to main menu
 `
 
 const (
-	pageLimit = 10
-
 	booksKey      = "books"
+	bookModelsKey = "bookModels"
 	bookParamsKey = "bookParams"
 )
 
-func (r *Requester) ProcessBookCatalogActions() error {
+func (r *Requester) ProcessBookCatalogActions(ctx context.Context) error {
 	var (
 		menuItem int
 		err      error
 	)
 
-	r.cache.Set(bookParamsKey, dto.BookParamsDTO{Limit: pageLimit, Offset: 0})
+	r.cache.Set(bookParamsKey, newBookSearch(search.Query{}))
 	r.cache.Set(booksKey, make([]uuid.UUID, 0))
+	r.cache.Set(bookModelsKey, make(map[uuid.UUID]*jsonmodels.BookModel))
 
 	for {
 		fmt.Printf("\n\n%s", catalogMenu)
 
-		if menuItem, err = input.MenuItem(); err != nil {
+		if menuItem, err = r.in.MenuItem(); err != nil {
 			fmt.Printf("\n\n%s\n", err.Error())
 			continue
 		}
 
 		switch menuItem {
 		case 1:
-			if err = r.viewFirstPage(); err != nil {
+			if err = interruptible(ctx, r.viewFirstPage); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 2:
-			if err = r.viewNextPage(); err != nil {
+			if err = interruptible(ctx, r.viewNextPage); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 3:
-			if err = r.ViewBook(); err != nil {
+			if err = interruptible(ctx, r.ViewBook); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 4:
-			if err = r.AddToFavorites(); err != nil {
+			if err = interruptible(ctx, r.AddToFavorites); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 5:
-			if err = r.ReserveBook(); err != nil {
+			if err = interruptible(ctx, r.ReserveBook); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 6:
-			if err = r.viewBookRatings(); err != nil {
+			if err = interruptible(ctx, r.viewBookRatings); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 7:
-			if err = r.addNewBookRating(); err != nil {
+			if err = interruptible(ctx, r.addNewBookRating); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 8:
+			if err = interruptible(ctx, r.viewPrevPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 9:
+			if err = interruptible(ctx, r.jumpToPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 10:
+			if err = interruptible(ctx, r.viewStartPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 11:
+			if err = interruptible(ctx, r.viewLastPage); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 12:
+			if err = interruptible(ctx, r.changePageSize); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 0:
@@ -83,462 +108,136 @@ func (r *Requester) ProcessBookCatalogActions() error {
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
-	}
-}
-func (r *Requester) viewFirstPage() error {
-	var bookParams dto.BookParamsDTO
-	var bookPagesID []uuid.UUID
-
-	isWithParams, err := input.IsWithParams()
-	if err != nil {
-		return err
-	}
-
-	if isWithParams {
-		if bookParams, err = input.Params(); err != nil {
-			return err
-		}
-	}
-
-	bookParams.Limit = pageLimit
-	bookParams.Offset = 0
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/books",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		QueryParams: map[string]string{
-			"title":           bookParams.Title,
-			"author":          bookParams.Author,
-			"publisher":       bookParams.Publisher,
-			"copies_number":   fmt.Sprintf("%d", bookParams.CopiesNumber),
-			"rarity":          bookParams.Rarity,
-			"genre":           bookParams.Genre,
-			"publishing_year": fmt.Sprintf("%d", bookParams.PublishingYear),
-			"language":        bookParams.Language,
-			"age_limit":       fmt.Sprintf("%d", bookParams.AgeLimit),
-			"limit":           fmt.Sprintf("%d", bookParams.Limit),
-			"offset":          fmt.Sprintf("%d", bookParams.Offset),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
-		return err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
-	var books []*jsonmodels.BookModel
-	if err = json.Unmarshal(response.Body, &books); err != nil {
-		return err
-	}
-
-	printBooks(books, 0)
-	copyBookIDsToArray(&bookPagesID, books)
-	r.cache.Set(booksKey, bookPagesID)
-	r.cache.Set(
-		bookParamsKey,
-		dto.BookParamsDTO{
-			Limit:  pageLimit,
-			Offset: bookParams.Offset + pageLimit,
-		},
-	)
 
-	return nil
-}
-
-func (r *Requester) viewNextPage() error {
-	var bookParams dto.BookParamsDTO
-	if err := r.cache.Get(bookParamsKey, &bookParams); err != nil {
-		return err
-	}
-
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
-	}
-
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/books",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		QueryParams: map[string]string{
-			"title":           bookParams.Title,
-			"author":          bookParams.Author,
-			"publisher":       bookParams.Publisher,
-			"copies_number":   fmt.Sprintf("%d", bookParams.CopiesNumber),
-			"rarity":          bookParams.Rarity,
-			"genre":           bookParams.Genre,
-			"publishing_year": fmt.Sprintf("%d", bookParams.PublishingYear),
-			"language":        bookParams.Language,
-			"age_limit":       fmt.Sprintf("%d", bookParams.AgeLimit),
-			"limit":           fmt.Sprintf("%d", bookParams.Limit),
-			"offset":          fmt.Sprintf("%d", bookParams.Offset),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
-		return err
-	}
-
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
+		// сессия закончилась - выходим, меню читателя вернет на вход
+		if errors.Is(err, client.ErrSessionExpired) {
+			return nil
 		}
-		return errors.New(info)
 	}
-
-	var books []*jsonmodels.BookModel
-	if err = json.Unmarshal(response.Body, &books); err != nil {
-		return err
-	}
-
-	printBooks(books, bookParams.Offset)
-	copyBookIDsToArray(&bookPagesID, books)
-	r.cache.Set(booksKey, bookPagesID)
-	r.cache.Set(
-		bookParamsKey,
-		dto.BookParamsDTO{
-			Limit:  pageLimit,
-			Offset: bookParams.Offset + pageLimit,
-		},
-	)
-
-	return nil
 }
-
-func (r *Requester) ViewBook() error {
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
-	}
-
-	num, err := input.BookPagesNumber()
+func (r *Requester) ViewBook(ctx context.Context) error {
+	selected, err := r.selectBook()
 	if err != nil {
 		return err
 	}
 
-	if num > len(bookPagesID) || num < 0 {
-		return errors.New("book number out of range")
-	}
-
-	bookID := bookPagesID[num]
-
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + fmt.Sprintf("/books/%s", bookID.String()),
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
+	book, err := r.client.GetBook(ctx, selected.id)
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
-	var book *jsonmodels.BookModel
-	if err = json.Unmarshal(response.Body, &book); err != nil {
-		return err
-	}
-
-	avgRating, err := r.getAvgRatingForBook(bookID)
+	avgRating, err := r.getAvgRatingForBook(ctx, selected.id)
 	if err != nil {
 		return err
 	}
 
-	printBook(book, avgRating, num)
+	r.printBook(book, avgRating, selected.num)
 
 	return nil
-
 }
 
-func (r *Requester) getAvgRatingForBook(bookID uuid.UUID) (float32, error) {
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/ratings/avg",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		QueryParams: map[string]string{
-			"book_id": bookID.String(),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
-		return -1, err
-	}
-
-	if response.StatusCode == http.StatusNotFound {
+func (r *Requester) getAvgRatingForBook(ctx context.Context, bookID uuid.UUID) (float32, error) {
+	avgRating, err := r.client.GetAvgRating(ctx, bookID)
+	if client.IsNotFound(err) {
 		return -1, nil
 	}
-
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return -1, err
-		}
-		return -1, errors.New(info)
-	}
-
-	var avgRating dto.AvgRatingDTO
-	if err = json.Unmarshal(response.Body, &avgRating); err != nil {
+	if err != nil {
 		return -1, err
 	}
 
-	return avgRating.AvgRating, nil
+	return avgRating, nil
 }
 
-func (r *Requester) AddToFavorites() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
-
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
-	}
-
-	num, err := input.BookPagesNumber()
+// AddToFavorites добавляет в избранное одну или несколько книг
+func (r *Requester) AddToFavorites(ctx context.Context) error {
+	selected, err := r.selectBooks()
 	if err != nil {
 		return err
 	}
 
-	if num > len(bookPagesID) || num < 0 {
-		return errors.New("book number out of range")
-	}
-
-	bookID := bookPagesID[num]
-
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/api/favorites",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Body:    bookID,
-		Timeout: 10 * time.Second,
+	if len(selected) > 1 {
+		return r.forEachBook(ctx, "Adding to favorites", selected, r.addToFavorites)
 	}
 
-	response, err := SendRequest(request)
-	if err != nil {
+	if err = r.addToFavorites(ctx, selected[0].id); err != nil {
 		return err
 	}
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errors.New("you are not authenticated")
-	}
-
-	if response.StatusCode != http.StatusCreated {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nBook successfully added to your favorites!\n")
 
 	return nil
 }
 
-func (r *Requester) viewBookRatings() error {
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
-	}
-
-	num, err := input.BookPagesNumber()
+func (r *Requester) viewBookRatings(ctx context.Context) error {
+	selected, err := r.selectBook()
 	if err != nil {
 		return err
 	}
 
-	if num > len(bookPagesID) || num < 0 {
-		return errors.New("book number out of range")
-	}
-
-	bookID := bookPagesID[num]
-
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/ratings",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		QueryParams: map[string]string{
-			"book_id": bookID.String(),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
+	ratings, err := r.client.GetRatings(ctx, selected.id)
 	if err != nil {
 		return err
 	}
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
 
-	var ratings []*dto.RatingOutputDTO
-	if err = json.Unmarshal(response.Body, &ratings); err != nil {
-		return err
-	}
-
-	printRatings(ratings, num)
+	r.printRatings(ratings, selected.num)
 
 	return nil
 }
 
-func (r *Requester) addNewBookRating() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
-
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
-	}
-
-	num, err := input.BookPagesNumber()
+func (r *Requester) addNewBookRating(ctx context.Context) error {
+	selected, err := r.selectBook()
 	if err != nil {
 		return err
 	}
 
-	if num > len(bookPagesID) || num < 0 {
-		return errors.New("book number out of range")
-	}
-
-	bookID := bookPagesID[num]
-
-	ratingDTO, err := input.RatingParams()
+	ratingDTO, err := r.in.RatingParams()
 	if err != nil {
 		return err
 	}
-	ratingDTO.BookID = bookID
-
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/api/ratings",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Body:    ratingDTO,
-		Timeout: 10 * time.Second,
-	}
+	ratingDTO.BookID = selected.id
 
-	response, err := SendRequest(request)
-	if err != nil {
+	if err = r.client.AddRating(ctx, ratingDTO); err != nil {
 		return err
 	}
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errors.New("you are not authenticated")
-	}
-
-	if response.StatusCode != http.StatusCreated {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nRating was successfully added!\n")
 
 	return nil
 }
 
-func (r *Requester) ReserveBook() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
-
-	var bookPagesID []uuid.UUID
-	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
-		return err
-	}
-
-	num, err := input.BookPagesNumber()
+// ReserveBook бронирует одну или несколько книг
+func (r *Requester) ReserveBook(ctx context.Context) error {
+	selected, err := r.selectBooks()
 	if err != nil {
 		return err
 	}
 
-	if num > len(bookPagesID) || num < 0 {
-		return errors.New("book number out of range")
+	if len(selected) > 1 {
+		return r.forEachBook(ctx, "Reservation", selected, r.client.Reserve)
 	}
 
-	bookID := bookPagesID[num]
-
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/api/reservations",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Body:    bookID,
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
+	if err = r.client.Reserve(ctx, selected[0].id); err != nil {
 		return err
 	}
 
-	if response.StatusCode == http.StatusUnauthorized {
-		return errors.New("you are not authenticated")
-	}
-
-	if response.StatusCode != http.StatusCreated {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nBook successfully reserved!\n")
 
 	return nil
 }
 
-func printBook(book *jsonmodels.BookModel, avgRating float32, num int) {
+// bookView - карточка книги для машиночитаемых форматов
+type bookView struct {
+	*jsonmodels.BookModel
+	AvgRating *float32 `json:"avg_rating"`
+}
+
+func (r *Requester) printBook(book *jsonmodels.BookModel, avgRating float32, num int) {
 	t := table.NewWriter()
-	t.SetTitle(fmt.Sprintf("Book №%d", num))
+	if num >= 0 {
+		t.SetTitle(fmt.Sprintf("Book №%d", num))
+	} else {
+		t.SetTitle("Book")
+	}
 	t.SetStyle(table.StyleBold)
 	t.Style().Format.Header = text.FormatTitle
 
@@ -558,28 +257,47 @@ func printBook(book *jsonmodels.BookModel, avgRating float32, num int) {
 		t.AppendRow(table.Row{"Avg Rating", fmt.Sprintf("%.1f", avgRating)})
 	}
 
-	fmt.Println(t.Render())
+	view := bookView{BookModel: book}
+	if avgRating != -1 {
+		view.AvgRating = &avgRating
+	}
+
+	r.render(t, view)
 }
 
-func printRatings(ratings []*dto.RatingOutputDTO, offset int) {
+func (r *Requester) printRatings(ratings []*dto.RatingOutputDTO, num int) {
 	t := table.NewWriter()
-	t.SetTitle(fmt.Sprintf("Отзывы на книгу №%d", offset))
+	if num >= 0 {
+		t.SetTitle(fmt.Sprintf("Отзывы на книгу №%d", num))
+	} else {
+		t.SetTitle("Отзывы на книгу")
+	}
 	t.SetStyle(table.StyleBold)
 	t.Style().Format.Header = text.FormatTitle
 	t.AppendHeader(table.Row{"No.", "Reader", "Review", "Rating"})
 
 	for i, rating := range ratings {
-		t.AppendRow(table.Row{offset + i, rating.Reader, rating.Review, rating.Rating})
+		t.AppendRow(table.Row{i, rating.Reader, rating.Review, rating.Rating})
 	}
-	fmt.Println(t.Render())
+	r.render(t, ratings)
 }
 
-func printBooks(books []*jsonmodels.BookModel, offset int) {
+func (r *Requester) printBooks(books []*jsonmodels.BookModel, offset int) {
+	r.printBookTable(books, offset, r.pageSize, false)
+}
+
+// printBookTable выводит страницу книг. В меню достаточно начала ID, по
+// которому книгу можно выбрать; withIDs выводит ID целиком, как его ждут
+// команды. В CSV и Markdown ID всегда полный
+func (r *Requester) printBookTable(books []*jsonmodels.BookModel, offset int, pageSize uint, withIDs bool) {
 	t := table.NewWriter()
-	t.SetTitle(fmt.Sprintf("Страница книг №%d", offset/pageLimit+1))
+	t.SetTitle(fmt.Sprintf("Страница книг №%d", offset/int(pageSize)+1))
 	t.SetStyle(table.StyleBold)
 	t.Style().Format.Header = text.FormatTitle
-	t.AppendHeader(table.Row{"No.", "Title", "Author"})
+
+	withIDs = withIDs || r.output == output.CSV || r.output == output.Markdown
+
+	t.AppendHeader(table.Row{"No.", "Title", "Author", "ID"})
 
 	t.SetColumnConfigs([]table.ColumnConfig{
 		{
@@ -589,13 +307,11 @@ func printBooks(books []*jsonmodels.BookModel, offset int) {
 	})
 
 	for i, book := range books {
-		t.AppendRow(table.Row{offset + i, book.Title, book.Author})
-	}
-	fmt.Println(t.Render())
-}
-
-func copyBookIDsToArray(bookIDs *[]uuid.UUID, books []*jsonmodels.BookModel) {
-	for _, book := range books {
-		*bookIDs = append(*bookIDs, book.ID)
+		var id interface{} = shortID(book.ID)
+		if withIDs {
+			id = book.ID
+		}
+		t.AppendRow(table.Row{offset + i, book.Title, book.Author, id})
 	}
+	r.render(t, books)
 }
@@ -0,0 +1,88 @@
+package requesters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+)
+
+// bulkResult - итог действия над одной из выбранных книг
+type bulkResult struct {
+	Num   int       `json:"num"`
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	OK    bool      `json:"ok"`
+	Error string    `json:"error,omitempty"`
+}
+
+// forEachBook выполняет действие над каждой выбранной книгой по очереди и
+// выводит таблицу итогов. Ошибка одной книги не останавливает остальные;
+// при отмене или истечении сессии оставшиеся книги не обрабатываются
+func (r *Requester) forEachBook(
+	ctx context.Context,
+	action string,
+	selected []selection,
+	do func(ctx context.Context, bookID uuid.UUID) error,
+) error {
+	var (
+		results = make([]bulkResult, 0, len(selected))
+		stopErr error
+	)
+
+	for _, s := range selected {
+		result := bulkResult{Num: s.num, ID: s.id, Title: r.bookTitle(s.id)}
+
+		if stopErr != nil {
+			result.Error = "not processed"
+		} else if err := do(ctx, s.id); err != nil {
+			result.Error = err.Error()
+			if ctx.Err() != nil || errors.Is(err, client.ErrSessionExpired) {
+				stopErr = err
+			}
+		} else {
+			result.OK = true
+		}
+
+		results = append(results, result)
+	}
+
+	r.printBulkResults(action, results)
+
+	return stopErr
+}
+
+func (r *Requester) printBulkResults(action string, results []bulkResult) {
+	t := table.NewWriter()
+	t.SetTitle(action)
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+	t.AppendHeader(table.Row{"No.", "Title", "Result"})
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Title", WidthMax: 50},
+		{Name: "Result", WidthMax: 60},
+	})
+
+	succeeded := 0
+	for _, result := range results {
+		var num interface{} = result.Num
+		if result.Num < 0 {
+			num = "-"
+		}
+
+		status := "ok"
+		if !result.OK {
+			status = "failed: " + result.Error
+		} else {
+			succeeded++
+		}
+
+		t.AppendRow(table.Row{num, result.Title, status})
+	}
+	t.AppendFooter(table.Row{"", "Total", fmt.Sprintf("%d ok, %d failed", succeeded, len(results)-succeeded)})
+
+	r.render(t, results)
+}
@@ -0,0 +1,593 @@
+package requesters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Коды завершения неинтерактивных команд
+const (
+	ExitOK           = 0
+	ExitError        = 1
+	ExitUsage        = 2
+	ExitUnauthorized = 3 // нет учетных данных, сессия истекла, 401 или 403
+	ExitNotFound     = 4
+	ExitConflict     = 5
+	ExitUnavailable  = 6 // API недоступен или ответил 5xx
+)
+
+const commandsUsage = `Usage: booksmart [flags] <command> [args]
+
+Commands:
+	books list [--title T] [--author A] [--publisher P] [--genre G] [--rarity R]
+	           [--language L] [--year Y] [--age-limit N] [--copies N] [--limit N] [--offset N]
+	           [key:value ...], e.g. genre:novel,poetry year:1850-1900 age:-12
+	books show <book-id>
+	books ratings <book-id>
+	books rate <book-id> --rating 1..5 [--review TEXT]
+	books favorite <book-id>
+	books reserve <book-id>
+	reservations list [key:value ...], e.g. state:issued,extended from:2024-09-01 sort:-return
+	reservations show <reservation-id|No.>
+	reservations extend <reservation-id|No.>
+	libcard create
+	libcard update
+	libcard show
+	admin books add --from book.json
+	admin books delete <book-id>
+	admin reservations <book-id>
+	admin dashboard [key:value ...], e.g. overdue:yes reader:89999999999 book:war
+	run [--stop-on-error] <script|->
+
+Commands that need an account sign in with the saved session (--session-store
+and BOOKSMART_SESSION_PASSPHRASE), the profile (--profile) or BOOKSMART_PHONE
+and BOOKSMART_PASSWORD.
+`
+
+// usageError - неверный вызов команды
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+var errNoCredentials = fmt.Errorf(
+	"%w: use --session-store, --profile or BOOKSMART_PHONE and BOOKSMART_PASSWORD",
+	client.ErrNotAuthenticated,
+)
+
+// command - неинтерактивная команда. role - под какой ролью нужно войти
+// перед ее выполнением, пусто - вход не нужен
+type command struct {
+	path []string
+	role string
+	run  func(ctx context.Context, args []string) error
+}
+
+func (r *Requester) commands() []command {
+	return []command{
+		{path: []string{"books", "list"}, run: r.cmdListBooks},
+		{path: []string{"books", "show"}, run: r.cmdShowBook},
+		{path: []string{"books", "ratings"}, run: r.cmdBookRatings},
+		{path: []string{"books", "rate"}, role: session.RoleReader, run: r.cmdRateBook},
+		{path: []string{"books", "favorite"}, role: session.RoleReader, run: r.cmdAddToFavorites},
+		{path: []string{"books", "reserve"}, role: session.RoleReader, run: r.cmdReserveBook},
+		{path: []string{"reservations", "list"}, role: session.RoleReader, run: r.cmdListReservations},
+		{path: []string{"reservations", "show"}, role: session.RoleReader, run: r.cmdShowReservation},
+		{path: []string{"reservations", "extend"}, role: session.RoleReader, run: r.cmdExtendReservation},
+		{path: []string{"libcard", "create"}, role: session.RoleReader, run: noArgs(r.CreateLibCard)},
+		{path: []string{"libcard", "update"}, role: session.RoleReader, run: noArgs(r.UpdateLibCard)},
+		{path: []string{"libcard", "show"}, role: session.RoleReader, run: noArgs(r.ViewLibCard)},
+		{path: []string{"admin", "books", "add"}, role: session.RoleAdmin, run: r.cmdAddBook},
+		{path: []string{"admin", "books", "delete"}, role: session.RoleAdmin, run: r.cmdDeleteBook},
+		{path: []string{"admin", "reservations"}, role: session.RoleAdmin, run: r.cmdBookReservations},
+		{path: []string{"admin", "dashboard"}, role: session.RoleAdmin, run: r.cmdDashboard},
+		{path: []string{"run"}, run: r.cmdRun},
+	}
+}
+
+// Exec выполняет команду args без интерактивного меню и возвращает код завершения
+func (r *Requester) Exec(ctx context.Context, args []string) int {
+	if args[0] == "help" {
+		fmt.Print(commandsUsage)
+		return ExitOK
+	}
+
+	cmd, rest, ok := r.findCommand(args)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", strings.Join(args, " "), commandsUsage)
+		return ExitUsage
+	}
+
+	err := r.execCommand(ctx, cmd, rest)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+
+	// отдельной проверки API перед командой нет: недоступность видна по первому запросу
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		err = r.unavailableError(err)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	return ExitCode(err)
+}
+
+// findCommand ищет команду с самым длинным совпадающим путем
+func (r *Requester) findCommand(args []string) (command, []string, bool) {
+	var (
+		found command
+		ok    bool
+	)
+
+	for _, cmd := range r.commands() {
+		if len(cmd.path) > len(args) || len(cmd.path) <= len(found.path) {
+			continue
+		}
+		if strings.Join(args[:len(cmd.path)], " ") == strings.Join(cmd.path, " ") {
+			found, ok = cmd, true
+		}
+	}
+
+	return found, args[len(found.path):], ok
+}
+
+func (r *Requester) execCommand(ctx context.Context, cmd command, args []string) error {
+	if cmd.role != "" {
+		if err := r.authenticate(ctx, cmd.role); err != nil {
+			return err
+		}
+	}
+
+	return interruptible(ctx, func(ctx context.Context) error {
+		return cmd.run(ctx, args)
+	})
+}
+
+// authenticate входит без ввода с клавиатуры: по сохраненной сессии, а если ее
+// нет - по учетным данным профиля или из окружения
+func (r *Requester) authenticate(ctx context.Context, role string) error {
+	// в пакетном режиме вход мог выполнить предыдущий шаг
+	if r.client.IsAuthenticated() {
+		return nil
+	}
+
+	if r.profile != nil {
+		r.setSessionStore(r.sessionStoreFor(r.profile.Name))
+	} else if r.sessionStore && r.sessionPassphrase != "" {
+		r.setSessionStore(r.sessionStoreFor(""))
+	}
+
+	if r.sessions != nil {
+		_, err := r.restoreSession(ctx)
+		if !errors.Is(err, session.ErrNoSession) {
+			return err
+		}
+	}
+
+	signIn := dto.ReaderSignInDTO{PhoneNumber: r.phone, Password: r.password}
+	if r.profile != nil {
+		role = r.profile.Role
+		signIn.PhoneNumber = r.profile.PhoneNumber
+		if r.profile.PasswordEnv != "" {
+			signIn.Password = os.Getenv(r.profile.PasswordEnv)
+		}
+	}
+	if signIn.PhoneNumber == "" || signIn.Password == "" {
+		return errNoCredentials
+	}
+
+	r.setRole(role)
+
+	var err error
+	if role == session.RoleAdmin {
+		_, err = r.client.SignInAsAdmin(ctx, signIn)
+	} else {
+		_, err = r.client.SignIn(ctx, signIn)
+	}
+
+	// отказ во входе (неверный телефон или пароль) - ошибка авторизации, а не 404
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError {
+		return fmt.Errorf("%w: %w", client.ErrNotAuthenticated, err)
+	}
+
+	return err
+}
+
+// ExitCode сопоставляет ошибку команды с кодом завершения
+func ExitCode(err error) int {
+	var (
+		usageErr usageError
+		apiErr   *client.APIError
+		netErr   net.Error
+	)
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.Is(err, client.ErrNotAuthenticated), errors.Is(err, client.ErrSessionExpired),
+		client.IsForbidden(err):
+		return ExitUnauthorized
+	case client.IsNotFound(err):
+		return ExitNotFound
+	case client.IsConflict(err):
+		return ExitConflict
+	case errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError,
+		errors.As(err, &netErr):
+		return ExitUnavailable
+	default:
+		return ExitError
+	}
+}
+
+func noArgs(operation func(ctx context.Context) error) func(context.Context, []string) error {
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 0 {
+			return usageError{msg: fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))}
+		}
+		return operation(ctx)
+	}
+}
+
+// newFlagSet создает набор флагов команды; ошибки разбора - usageError
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		return usageError{msg: err.Error()}
+	}
+
+	return err
+}
+
+// idArg разбирает единственный позиционный аргумент - UUID
+func idArg(args []string, what string) (uuid.UUID, error) {
+	if len(args) != 1 {
+		return uuid.Nil, usageError{msg: fmt.Sprintf("expected exactly one %s id", what)}
+	}
+
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return uuid.Nil, usageError{msg: fmt.Sprintf("invalid %s id %q", what, args[0])}
+	}
+
+	return id, nil
+}
+
+func (r *Requester) cmdListBooks(ctx context.Context, args []string) error {
+	var (
+		query  search.Query
+		limit  uint
+		offset int
+	)
+
+	params := &query.Params
+	fs := newFlagSet("books list")
+	fs.StringVar(&params.Title, "title", "", "book title")
+	fs.StringVar(&params.Author, "author", "", "book author")
+	fs.StringVar(&params.Publisher, "publisher", "", "book publisher")
+	fs.StringVar(&params.Genre, "genre", "", "book genre")
+	fs.StringVar(&params.Rarity, "rarity", "", "book rarity")
+	fs.StringVar(&params.Language, "language", "", "book language")
+	fs.UintVar(&params.PublishingYear, "year", 0, "publishing year")
+	fs.UintVar(&params.AgeLimit, "age-limit", 0, "age limit")
+	fs.UintVar(&params.CopiesNumber, "copies", 0, "number of copies")
+	fs.UintVar(&limit, "limit", r.pageSize, "books per page")
+	fs.IntVar(&offset, "offset", 0, "number of books to skip")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := input.ParseFilters(&query, fs.Args()); err != nil {
+		return usageError{msg: err.Error()}
+	}
+	if offset < 0 {
+		return usageError{msg: "offset must not be negative"}
+	}
+	if limit == 0 {
+		return usageError{msg: "limit must be positive"}
+	}
+
+	books, err := search.Page(ctx, r.client.ListBooks, query, offset, limit)
+	if err != nil {
+		return err
+	}
+
+	r.printBookTable(books, offset, limit, true)
+
+	return nil
+}
+
+func (r *Requester) cmdShowBook(ctx context.Context, args []string) error {
+	bookID, err := idArg(args, "book")
+	if err != nil {
+		return err
+	}
+
+	book, err := r.client.GetBook(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	avgRating, err := r.getAvgRatingForBook(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	r.printBook(book, avgRating, -1)
+
+	return nil
+}
+
+func (r *Requester) cmdBookRatings(ctx context.Context, args []string) error {
+	bookID, err := idArg(args, "book")
+	if err != nil {
+		return err
+	}
+
+	ratings, err := r.client.GetRatings(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	r.printRatings(ratings, -1)
+
+	return nil
+}
+
+func (r *Requester) cmdRateBook(ctx context.Context, args []string) error {
+	var rating dto.RatingInputDTO
+
+	fs := newFlagSet("books rate")
+	fs.IntVar(&rating.Rating, "rating", 0, "rating from 1 to 5")
+	fs.StringVar(&rating.Review, "review", "", "review text")
+
+	// флаги могут идти и до, и после ID книги
+	var positional []string
+	for len(args) > 0 {
+		if err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		if args = fs.Args(); len(args) > 0 {
+			positional, args = append(positional, args[0]), args[1:]
+		}
+	}
+
+	bookID, err := idArg(positional, "book")
+	if err != nil {
+		return err
+	}
+	if rating.Rating < 1 || rating.Rating > 5 {
+		return usageError{msg: "rating must be from 1 to 5"}
+	}
+	rating.BookID = bookID
+
+	if err = r.client.AddRating(ctx, rating); err != nil {
+		return err
+	}
+
+	fmt.Println("Rating successfully added!")
+
+	return nil
+}
+
+func (r *Requester) cmdAddToFavorites(ctx context.Context, args []string) error {
+	bookID, err := idArg(args, "book")
+	if err != nil {
+		return err
+	}
+
+	if err = r.addToFavorites(ctx, bookID); err != nil {
+		return err
+	}
+
+	fmt.Println("Book successfully added to favorites!")
+
+	return nil
+}
+
+func (r *Requester) cmdReserveBook(ctx context.Context, args []string) error {
+	bookID, err := idArg(args, "book")
+	if err != nil {
+		return err
+	}
+
+	if err = r.client.Reserve(ctx, bookID); err != nil {
+		return err
+	}
+
+	fmt.Println("Book successfully reserved!")
+
+	return nil
+}
+
+func (r *Requester) cmdListReservations(ctx context.Context, args []string) error {
+	var query search.ReservationQuery
+	if err := input.ParseReservationFilterArgs(&query, args); err != nil {
+		return usageError{msg: err.Error()}
+	}
+
+	reservations, err := r.client.ListReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = r.resolveReservationFilters(ctx, &query, reservations); err != nil {
+		return err
+	}
+	shown := query.Apply(reservations)
+	r.printReservationTable(ctx, "Reservations", shown, reservationNumbers(reservations, shown), true)
+
+	return nil
+}
+
+func (r *Requester) reservationArg(ctx context.Context, args []string) (uuid.UUID, error) {
+	if len(args) == 1 {
+		if num, err := strconv.Atoi(args[0]); err == nil {
+			reservations, err := r.client.ListReservations(ctx)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			if num < 0 || num >= len(reservations) {
+				return uuid.Nil, usageError{msg: "reservation number out of range"}
+			}
+			return reservations[num].ID, nil
+		}
+	}
+
+	return idArg(args, "reservation")
+}
+
+func (r *Requester) cmdShowReservation(ctx context.Context, args []string) error {
+	reservationID, err := r.reservationArg(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	reservation, err := r.client.GetReservation(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+
+	r.printReservationTable(ctx, "Reservation", []*jsonmodels.ReservationModel{reservation}, nil, true)
+
+	return nil
+}
+
+// cmdExtendReservation продлевает бронь без подтверждения
+func (r *Requester) cmdExtendReservation(ctx context.Context, args []string) error {
+	reservationID, err := r.reservationArg(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	reservation, err := r.client.GetReservation(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	books := r.lookupBooks(ctx, []uuid.UUID{reservation.BookID})
+	if err = checkReservationAction(reservation, books[reservation.BookID], actionExtend); err != nil {
+		return err
+	}
+
+	return r.extendReservation(ctx, reservation)
+}
+
+func (r *Requester) cmdAddBook(ctx context.Context, args []string) error {
+	fs := newFlagSet("admin books add")
+	from := fs.String("from", "", "JSON file with the book, - for stdin")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *from == "" || fs.NArg() > 0 {
+		return usageError{msg: "usage: admin books add --from book.json"}
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if *from == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*from)
+	}
+	if err != nil {
+		return err
+	}
+
+	var book dto.BookDTO
+	if err = json.Unmarshal(data, &book); err != nil {
+		return usageError{msg: fmt.Sprintf("parsing book %s: %v", *from, err)}
+	}
+
+	if err = r.client.AddBook(ctx, book); err != nil {
+		return err
+	}
+
+	fmt.Println("Book successfully created!")
+
+	return nil
+}
+
+func (r *Requester) cmdDeleteBook(ctx context.Context, args []string) error {
+	bookID, err := idArg(args, "book")
+	if err != nil {
+		return err
+	}
+
+	if err = r.getReservationsByBook(ctx, bookID); err != nil {
+		return err
+	}
+
+	if err = r.client.DeleteBook(ctx, bookID); err != nil {
+		return err
+	}
+
+	fmt.Println("Book successfully deleted!")
+
+	return nil
+}
+
+func (r *Requester) cmdBookReservations(ctx context.Context, args []string) error {
+	bookID, err := idArg(args, "book")
+	if err != nil {
+		return err
+	}
+
+	reservations, err := r.client.ListReservationsByBook(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	r.printReservationTable(ctx, "Reservations", reservations, nil, true)
+
+	return nil
+}
+
+func (r *Requester) cmdDashboard(ctx context.Context, args []string) error {
+	var query search.ReservationQuery
+	if err := input.ParseReservationFilterArgs(&query, args); err != nil {
+		return usageError{msg: err.Error()}
+	}
+
+	reservations, err := r.loadLibraryReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = r.resolveReservationFilters(ctx, &query, reservations); err != nil {
+		return err
+	}
+	r.printDashboardTable(ctx, "Library reservations", reservations, query.Apply(reservations), true)
+
+	return nil
+}
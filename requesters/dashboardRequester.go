@@ -0,0 +1,525 @@
+package requesters
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+const dashboardMenu = `Reservations dashboard:
+	1 -- reload reservations
+	2 -- filter reservations
+	3 -- view reservation
+	4 -- view reservation's book
+	5 -- view reader
+	6 -- sort by return date
+	0 -- go to main menu
+`
+
+const (
+	dashboardKey        = "dashboard"
+	dashboardFiltersKey = "dashboardFilters"
+)
+
+// dashboard - загруженные брони библиотеки; loadedAt нулевое, пока они не загружены
+type dashboard struct {
+	reservations []*jsonmodels.ReservationModel
+	loadedAt     time.Time
+}
+
+// ProcessDashboardActions - брони всех читателей библиотеки для администратора
+func (r *Requester) ProcessDashboardActions(ctx context.Context) error {
+	var (
+		menuItem int
+		err      error
+	)
+
+	// загрузка стоит запроса на каждую книгу каталога, поэтому брони и фильтры
+	// сохраняются до выхода из аккаунта, а обновляет их только пункт 1
+	var loaded dashboard
+	if err = r.cache.Get(dashboardKey, &loaded); err != nil {
+		r.cache.Set(dashboardKey, dashboard{})
+		r.cache.Set(dashboardFiltersKey, search.ReservationQuery{})
+	}
+
+	for {
+		fmt.Printf("\n\n%s", dashboardMenu)
+
+		if menuItem, err = r.in.MenuItem(); err != nil {
+			fmt.Printf("\n\n%s\n", err.Error())
+			continue
+		}
+
+		switch menuItem {
+		case 1:
+			if err = interruptible(ctx, r.reloadDashboard); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 2:
+			if err = interruptible(ctx, r.filterDashboard); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 3:
+			if err = interruptible(ctx, r.viewDashboardReservation); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 4:
+			if err = interruptible(ctx, r.viewDashboardBook); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 5:
+			if err = interruptible(ctx, r.viewDashboardReader); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 6:
+			if err = interruptible(ctx, r.sortDashboardByReturnDate); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 0:
+			return nil
+		default:
+			fmt.Printf("\n\nWrong menu item!\n")
+		}
+
+		// сессия закончилась - выходим, меню администратора вернет на вход
+		if errors.Is(err, client.ErrSessionExpired) {
+			return nil
+		}
+	}
+}
+
+// loadLibraryReservations собирает брони всех читателей. API отдает их
+// только по книге, поэтому каталог просматривается целиком, а брони книг
+// запрашиваются параллельно, не больше maxBookLookups запросов сразу
+func (r *Requester) loadLibraryReservations(ctx context.Context) ([]*jsonmodels.ReservationModel, error) {
+	books, err := search.All(ctx, r.client.ListBooks, search.Query{})
+	if err != nil {
+		return nil, err
+	}
+	r.rememberKnownBooks(books)
+
+	var (
+		perBook = make([][]*jsonmodels.ReservationModel, len(books))
+		errs    = make([]error, len(books))
+	)
+	parallel(ctx, len(books), maxBookLookups, func(ctx context.Context, i int) {
+		reservations, err := r.client.ListReservationsByBook(ctx, books[i].ID)
+		// у книги нет броней
+		if client.IsNotFound(err) {
+			return
+		}
+		perBook[i], errs[i] = reservations, err
+	})
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, err = range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var reservations []*jsonmodels.ReservationModel
+	for _, bookReservations := range perBook {
+		reservations = append(reservations, bookReservations...)
+	}
+
+	// номера строк не должны зависеть от порядка ответов
+	slices.SortStableFunc(reservations, func(a, b *jsonmodels.ReservationModel) int {
+		return cmp.Or(a.IssueDate.Compare(b.IssueDate), strings.Compare(a.ID.String(), b.ID.String()))
+	})
+
+	return reservations, nil
+}
+
+func (r *Requester) rememberKnownBooks(books []*jsonmodels.BookModel) {
+	r.booksMu.Lock()
+	defer r.booksMu.Unlock()
+
+	for _, book := range books {
+		r.knownBooks[book.ID] = book
+	}
+}
+
+func (r *Requester) reloadDashboard(ctx context.Context) error {
+	reservations, err := r.loadLibraryReservations(ctx)
+	if err != nil {
+		return err
+	}
+	r.cache.Set(dashboardKey, dashboard{reservations: reservations, loadedAt: time.Now()})
+
+	return r.showDashboard(ctx)
+}
+
+// dashboardReservations возвращает загруженные брони, при первом обращении
+// загружая их. Пустой список тоже считается загруженным
+func (r *Requester) dashboardReservations(ctx context.Context) ([]*jsonmodels.ReservationModel, error) {
+	var loaded dashboard
+	if err := r.cache.Get(dashboardKey, &loaded); err != nil {
+		return nil, err
+	}
+	if !loaded.loadedAt.IsZero() {
+		return loaded.reservations, nil
+	}
+
+	reservations, err := r.loadLibraryReservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(dashboardKey, dashboard{reservations: reservations, loadedAt: time.Now()})
+
+	return reservations, nil
+}
+
+func (r *Requester) showDashboard(ctx context.Context) error {
+	var query search.ReservationQuery
+	if err := r.cache.Get(dashboardFiltersKey, &query); err != nil {
+		return err
+	}
+
+	reservations, err := r.dashboardReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = r.resolveReservationFilters(ctx, &query, reservations); err != nil {
+		return err
+	}
+
+	title := "Library reservations"
+	if query.IsSet() {
+		title = fmt.Sprintf("Library reservations (%s)", query)
+	}
+	r.printDashboardTable(ctx, title, reservations, query.Apply(reservations), false)
+
+	return nil
+}
+
+func (r *Requester) filterDashboard(ctx context.Context) error {
+	query, err := r.in.ReservationFilters()
+	if err != nil {
+		return err
+	}
+	r.cache.Set(dashboardFiltersKey, query)
+
+	return r.showDashboard(ctx)
+}
+
+// sortDashboardByReturnDate сортирует брони по дате возврата; повторный
+// выбор меняет порядок на обратный
+func (r *Requester) sortDashboardByReturnDate(ctx context.Context) error {
+	var query search.ReservationQuery
+	if err := r.cache.Get(dashboardFiltersKey, &query); err != nil {
+		return err
+	}
+
+	query.Desc = query.SortBy == search.SortByReturnDate && !query.Desc
+	query.SortBy = search.SortByReturnDate
+	r.cache.Set(dashboardFiltersKey, query)
+
+	return r.showDashboard(ctx)
+}
+
+// resolveReservationFilters готовит фильтры book и reader: книгу можно искать
+// по названию, а читателя - по номеру телефона, который заменяется его ID
+func (r *Requester) resolveReservationFilters(
+	ctx context.Context,
+	query *search.ReservationQuery,
+	reservations []*jsonmodels.ReservationModel,
+) error {
+	if query.Book != "" {
+		books := r.lookupBooks(ctx, reservationBookIDs(reservations))
+		query.BookTitle = func(bookID uuid.UUID) string {
+			if book, ok := books[bookID]; ok {
+				return book.Title
+			}
+			return ""
+		}
+	}
+
+	if isPhoneNumber(query.Reader) {
+		reader, err := r.readerByPhone(ctx, query.Reader)
+		if err != nil {
+			return err
+		}
+		query.Reader = reader.ID.String()
+	}
+
+	return nil
+}
+
+// readerByPhone находит читателя по номеру телефона и запоминает его, чтобы
+// показывать в таблицах имя вместо ID
+func (r *Requester) readerByPhone(ctx context.Context, phone string) (*jsonmodels.ReaderModel, error) {
+	reader, err := r.client.GetReaderByPhoneNumber(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	r.knownReaders[reader.ID] = reader
+
+	return reader, nil
+}
+
+// isPhoneNumber сообщает, что строка - номер телефона из 11 цифр. Началом
+// ID она быть не может: в 9-м символе ID стоит дефис
+func isPhoneNumber(value string) bool {
+	return len(value) == 11 && strings.Trim(value, "0123456789") == ""
+}
+
+// selectDashboardReservation запрашивает бронь из загруженных
+func (r *Requester) selectDashboardReservation(ctx context.Context) (*jsonmodels.ReservationModel, int, error) {
+	reservations, err := r.dashboardReservations(ctx)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	ref, err := r.in.ReservationRef()
+	if err != nil {
+		return nil, -1, err
+	}
+
+	selected, err := resolveRef(reservationIDs(reservations), ref, "reservation")
+	if err != nil {
+		return nil, -1, err
+	}
+	if selected.num < 0 {
+		return nil, -1, errors.New("reservation is not among the loaded ones, reload the dashboard")
+	}
+
+	return reservations[selected.num], selected.num, nil
+}
+
+func (r *Requester) viewDashboardReservation(ctx context.Context) error {
+	reservation, num, err := r.selectDashboardReservation(ctx)
+	if err != nil {
+		return err
+	}
+
+	books := r.lookupBooks(ctx, []uuid.UUID{reservation.BookID})
+
+	t := table.NewWriter()
+	t.SetTitle(fmt.Sprintf("Reservation №%d", num))
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+
+	t.AppendRow(table.Row{"ID", reservation.ID})
+	if book, ok := books[reservation.BookID]; ok {
+		t.AppendRow(table.Row{"Book", fmt.Sprintf("%q by %s", book.Title, book.Author)})
+	}
+	t.AppendRow(table.Row{"Book ID", reservation.BookID})
+	if reader, ok := r.knownReaders[reservation.ReaderID]; ok {
+		t.AppendRow(table.Row{"Reader", fmt.Sprintf("%s (%s)", reader.Fio, reader.PhoneNumber)})
+	}
+	t.AppendRow(table.Row{"Reader ID", reservation.ReaderID})
+	t.AppendRow(table.Row{"Issue Date", formatDate(reservation.IssueDate)})
+	t.AppendRow(table.Row{"Return Date", formatDate(reservation.ReturnDate)})
+	t.AppendRow(table.Row{"State", reservation.State})
+	t.AppendRow(table.Row{"Return", returnStatus(reservation)})
+
+	r.render(t, r.reservationViews([]*jsonmodels.ReservationModel{reservation}, books)[0])
+
+	return nil
+}
+
+// viewDashboardBook выводит карточку книги из брони и все ее брони
+func (r *Requester) viewDashboardBook(ctx context.Context) error {
+	reservation, _, err := r.selectDashboardReservation(ctx)
+	if err != nil {
+		return err
+	}
+
+	book, err := r.client.GetBook(ctx, reservation.BookID)
+	if err != nil {
+		return err
+	}
+
+	avgRating, err := r.getAvgRatingForBook(ctx, book.ID)
+	if err != nil {
+		return err
+	}
+
+	r.printBook(book, avgRating, -1)
+
+	return r.printDashboardSubset(ctx, fmt.Sprintf("Reservations of %q", book.Title),
+		func(res *jsonmodels.ReservationModel) bool { return res.BookID == book.ID })
+}
+
+// viewDashboardReader выводит читателя и все его брони. API не отдает
+// читателя по ID, поэтому имя и телефон известны, только если читателя искали
+// по номеру телефона
+func (r *Requester) viewDashboardReader(ctx context.Context) error {
+	ref, err := r.in.ReaderRef()
+	if err != nil {
+		return err
+	}
+
+	var readerID uuid.UUID
+	if isPhoneNumber(ref) {
+		reader, err := r.readerByPhone(ctx, ref)
+		if err != nil {
+			return err
+		}
+		readerID = reader.ID
+	} else {
+		reservations, err := r.dashboardReservations(ctx)
+		if err != nil {
+			return err
+		}
+		selected, err := resolveRef(reservationIDs(reservations), ref, "reservation")
+		if err != nil {
+			return err
+		}
+		if selected.num < 0 {
+			return errors.New("reservation is not among the loaded ones, reload the dashboard")
+		}
+		readerID = reservations[selected.num].ReaderID
+	}
+
+	t := table.NewWriter()
+	t.SetTitle("Reader")
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+
+	t.AppendRow(table.Row{"ID", readerID})
+	reader, known := r.knownReaders[readerID]
+	if known {
+		t.AppendRow(table.Row{"FIO", reader.Fio})
+		t.AppendRow(table.Row{"Phone Number", reader.PhoneNumber})
+		t.AppendRow(table.Row{"Age", reader.Age})
+	} else {
+		t.AppendRow(table.Row{"FIO", "unknown, look the reader up by phone number"})
+	}
+
+	// пароль и роль из модели читателя не выводятся
+	view := readerView{ID: readerID}
+	if known {
+		view.Fio, view.PhoneNumber, view.Age = reader.Fio, reader.PhoneNumber, reader.Age
+	}
+	r.render(t, view)
+
+	return r.printDashboardSubset(ctx, "Reader's reservations",
+		func(res *jsonmodels.ReservationModel) bool { return res.ReaderID == readerID })
+}
+
+// readerView - читатель без пароля и роли для машиночитаемых форматов
+type readerView struct {
+	ID          uuid.UUID `json:"id"`
+	Fio         string    `json:"fio,omitempty"`
+	PhoneNumber string    `json:"phone_number,omitempty"`
+	Age         uint      `json:"age,omitempty"`
+}
+
+func (r *Requester) printDashboardSubset(ctx context.Context, title string, match func(*jsonmodels.ReservationModel) bool) error {
+	reservations, err := r.dashboardReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	var shown []*jsonmodels.ReservationModel
+	for _, reservation := range reservations {
+		if match(reservation) {
+			shown = append(shown, reservation)
+		}
+	}
+
+	r.printDashboardTable(ctx, title, reservations, shown, false)
+
+	return nil
+}
+
+func (r *Requester) readerLabel(readerID uuid.UUID) string {
+	if reader, ok := r.knownReaders[readerID]; ok {
+		return reader.Fio
+	}
+
+	return shortID(readerID)
+}
+
+// reservationTotals считает брони по состояниям и просроченные
+func reservationTotals(reservations []*jsonmodels.ReservationModel) string {
+	counts := make(map[string]int)
+	overdue := 0
+	for _, reservation := range reservations {
+		counts[reservation.State]++
+		if search.Overdue(reservation, time.Now()) {
+			overdue++
+		}
+	}
+
+	states := []string{reservationIssued, reservationExtended, reservationExpired, reservationClosed}
+	parts := make([]string, 0, len(states))
+	for _, state := range states {
+		parts = append(parts, fmt.Sprintf("%s %d", state, counts[state]))
+	}
+
+	return fmt.Sprintf("%d: %s; overdue %d", len(reservations), strings.Join(parts, ", "), overdue)
+}
+
+// printDashboardTable выводит брони читателей с итогами по состояниям.
+// Номера строк - номера в полном списке all
+func (r *Requester) printDashboardTable(
+	ctx context.Context,
+	title string,
+	all, shown []*jsonmodels.ReservationModel,
+	withIDs bool,
+) {
+	books := r.lookupBooks(ctx, reservationBookIDs(shown))
+
+	t := table.NewWriter()
+	t.SetTitle(title)
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+
+	withIDs = withIDs || r.output == output.CSV || r.output == output.Markdown
+
+	header := table.Row{"No.", "Book", "Reader", "Issue Date", "Return Date", "State", "Overdue"}
+	if withIDs {
+		header = append(header, "ID", "Book ID", "Reader ID")
+	}
+	t.AppendHeader(header)
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Book", WidthMax: 40},
+	})
+
+	nums := reservationNumbers(all, shown)
+	for i, reservation := range shown {
+		bookTitle := "-"
+		if book, ok := books[reservation.BookID]; ok {
+			bookTitle = book.Title
+		}
+
+		overdue := ""
+		if search.Overdue(reservation, time.Now()) {
+			overdue = "yes"
+		}
+
+		row := table.Row{
+			nums[i],
+			bookTitle,
+			r.readerLabel(reservation.ReaderID),
+			formatDate(reservation.IssueDate),
+			formatDate(reservation.ReturnDate),
+			reservation.State,
+			overdue,
+		}
+		if withIDs {
+			row = append(row, reservation.ID, reservation.BookID, reservation.ReaderID)
+		}
+		t.AppendRow(row)
+	}
+	t.AppendFooter(table.Row{"", "Total", reservationTotals(shown)})
+
+	r.render(t, r.reservationViews(shown, books))
+}
@@ -0,0 +1,71 @@
+package requesters
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	myCache "github.com/nikitalystsev/BookSmart-tech-ui/pkg/cache"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDashboardReservationsAreLoadedOnce(t *testing.T) {
+	tests := []struct {
+		name  string
+		books []*jsonmodels.BookModel
+		// запросы первой загрузки: страница каталога и брони каждой книги
+		wantRequests int32
+	}{
+		{name: "library without reservations", books: []*jsonmodels.BookModel{{ID: bookA}, {ID: bookB}}, wantRequests: 3},
+		{name: "empty catalog", wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if r.URL.Path == "/books" && r.URL.Query().Get("offset") == "0" && len(tt.books) > 0 {
+					_ = json.NewEncoder(w).Encode(tt.books)
+					return
+				}
+				// сервер отвечает 404 и на пустой каталог, и на книгу без броней
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			apiClient, err := client.NewClient(server.URL, client.DefaultTransportConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			apiClient.RestoreTokens(dto.ReaderTokensDTO{AccessToken: "access"}, time.Now())
+
+			r := &Requester{
+				client:     apiClient,
+				cache:      myCache.NewCache(),
+				knownBooks: make(map[uuid.UUID]*jsonmodels.BookModel),
+			}
+			r.cache.Set(dashboardKey, dashboard{})
+
+			for i := 0; i < 3; i++ {
+				reservations, err := r.dashboardReservations(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(reservations) != 0 {
+					t.Fatalf("reservations = %d, want 0", len(reservations))
+				}
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d (one load for all calls)", got, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package requesters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/favorites"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"os"
+	"time"
+)
+
+const favoritesMenu = `Favorites menu:
+	1 -- view favorites
+	2 -- view info about book
+	3 -- remove from favorites
+	4 -- move book in the list
+	5 -- reserve books
+	6 -- export favorites
+	0 -- go to main menu
+`
+
+// favoriteTitlesKey - названия книг из локального списка избранного. Отдельно от
+// bookModelsKey: там только полностью запрошенные книги, а в избранном хранятся
+// лишь название и автор
+const favoriteTitlesKey = "favoriteTitles"
+
+var errFavoritesUnavailable = errors.New("favorites are unavailable: user config directory is unknown")
+
+func (r *Requester) ProcessFavoritesActions(ctx context.Context) error {
+	r.cache.Set(favoriteTitlesKey, make(map[uuid.UUID]string))
+
+	for {
+		fmt.Printf("\n\n%s", favoritesMenu)
+
+		menuItem, err := r.in.MenuItem()
+		if err != nil {
+			fmt.Printf("\n\n%s\n", err.Error())
+			continue
+		}
+
+		switch menuItem {
+		case 1:
+			if err = interruptible(ctx, r.viewFavorites); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 2:
+			if err = interruptible(ctx, r.viewFavoriteBook); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 3:
+			if err = interruptible(ctx, r.removeFavorite); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 4:
+			if err = interruptible(ctx, r.moveFavorite); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 5:
+			if err = interruptible(ctx, r.reserveFavorites); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 6:
+			if err = interruptible(ctx, r.exportFavorites); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 0:
+			return nil
+		default:
+			fmt.Printf("\n\nWrong menu item!\n")
+		}
+
+		// сессия закончилась - выходим, меню читателя вернет на вход
+		if errors.Is(err, client.ErrSessionExpired) {
+			return nil
+		}
+	}
+}
+
+// favoritesOwner - ключ списка избранного текущего аккаунта: адрес API и ID
+// читателя из токена доступа
+func (r *Requester) favoritesOwner() (string, error) {
+	if r.favorites == nil {
+		return "", errFavoritesUnavailable
+	}
+
+	readerID, err := client.ReaderID(r.client.Tokens())
+	if err != nil {
+		return "", fmt.Errorf("cannot identify the reader: %w", err)
+	}
+
+	return r.client.BaseURL() + " " + readerID, nil
+}
+
+// loadFavorites возвращает избранное текущего аккаунта и запоминает книги,
+// чтобы их можно было выбрать по номеру или ID
+func (r *Requester) loadFavorites() (string, []favorites.Favorite, []uuid.UUID, error) {
+	owner, err := r.favoritesOwner()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	list, err := r.favorites.List(owner)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(list))
+	titles := make(map[uuid.UUID]string, len(list))
+	for i, f := range list {
+		ids[i] = f.BookID
+		titles[f.BookID] = f.Title
+	}
+	r.cache.Set(favoriteTitlesKey, titles)
+
+	return owner, list, ids, nil
+}
+
+// addToFavorites добавляет книгу в избранное на сервере и в локальный список.
+// Книга, которая уже была в избранном на сервере, тоже попадает в список
+func (r *Requester) addToFavorites(ctx context.Context, bookID uuid.UUID) error {
+	err := r.client.AddToFavorites(ctx, bookID)
+	if err != nil && !client.IsConflict(err) {
+		return err
+	}
+
+	if r.favorites != nil {
+		if rememberErr := r.rememberFavorite(ctx, bookID); rememberErr != nil && err == nil {
+			return fmt.Errorf("book added to favorites, but not saved to the local list: %w", rememberErr)
+		}
+	}
+
+	return err
+}
+
+func (r *Requester) rememberFavorite(ctx context.Context, bookID uuid.UUID) error {
+	owner, err := r.favoritesOwner()
+	if err != nil {
+		return err
+	}
+
+	book, ok := r.viewedBook(bookID)
+	if !ok {
+		if book, err = r.client.GetBook(ctx, bookID); err != nil {
+			return err
+		}
+	}
+
+	return r.favorites.Add(owner, favorites.Favorite{
+		BookID:  bookID,
+		Title:   book.Title,
+		Author:  book.Author,
+		AddedAt: time.Now(),
+	})
+}
+
+func (r *Requester) viewFavorites(_ context.Context) error {
+	_, list, _, err := r.loadFavorites()
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		fmt.Printf("\n\nYour favorites list is empty\n")
+		return nil
+	}
+
+	t := r.favoritesTable(list, false)
+	r.render(t, list)
+
+	return nil
+}
+
+// favoritesTable - те же столбцы, что и у страницы каталога; при экспорте ID
+// выводится целиком и добавляется дата
+func (r *Requester) favoritesTable(list []favorites.Favorite, export bool) table.Writer {
+	t := table.NewWriter()
+	t.SetTitle("Favorites")
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+
+	header := table.Row{"No.", "Title", "Author", "ID"}
+	if export {
+		header = append(header, "Added At")
+	}
+	t.AppendHeader(header)
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{
+			Name:     "Author",
+			WidthMax: 80,
+		},
+	})
+
+	for i, f := range list {
+		if export {
+			t.AppendRow(table.Row{i, f.Title, f.Author, f.BookID, f.AddedAt.Format(time.DateOnly)})
+		} else {
+			t.AppendRow(table.Row{i, f.Title, f.Author, shortID(f.BookID)})
+		}
+	}
+
+	return t
+}
+
+func (r *Requester) selectFavorite() (string, selection, error) {
+	owner, _, ids, err := r.loadFavorites()
+	if err != nil {
+		return "", selection{}, err
+	}
+
+	ref, err := r.in.BookRef()
+	if err != nil {
+		return "", selection{}, err
+	}
+
+	selected, err := resolveBook(ids, ref)
+	if err == nil && selected.num < 0 {
+		err = favorites.ErrNotFound
+	}
+
+	return owner, selected, err
+}
+
+func (r *Requester) viewFavoriteBook(ctx context.Context) error {
+	_, selected, err := r.selectFavorite()
+	if err != nil {
+		return err
+	}
+
+	book, err := r.client.GetBook(ctx, selected.id)
+	if err != nil {
+		return err
+	}
+
+	avgRating, err := r.getAvgRatingForBook(ctx, selected.id)
+	if err != nil {
+		return err
+	}
+
+	r.printBook(book, avgRating, selected.num)
+
+	return nil
+}
+
+// removeFavorite убирает книгу из локального списка. Удалить ее из избранного
+// на сервере нельзя - у API нет такого метода
+func (r *Requester) removeFavorite(_ context.Context) error {
+	owner, selected, err := r.selectFavorite()
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := r.in.Confirm(fmt.Sprintf("Remove %q from favorites?", r.bookTitle(selected.id)))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Printf("\n\nBook was not removed\n")
+		return nil
+	}
+
+	if err = r.favorites.Remove(owner, selected.id); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nBook removed from your favorites list\n")
+
+	return nil
+}
+
+func (r *Requester) moveFavorite(_ context.Context) error {
+	owner, selected, err := r.selectFavorite()
+	if err != nil {
+		return err
+	}
+
+	position, err := r.in.Position()
+	if err != nil {
+		return err
+	}
+
+	if err = r.favorites.Move(owner, selected.id, int(position)); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nBook moved\n")
+
+	return nil
+}
+
+func (r *Requester) reserveFavorites(ctx context.Context) error {
+	_, _, ids, err := r.loadFavorites()
+	if err != nil {
+		return err
+	}
+
+	refs, err := r.in.BookRefs()
+	if err != nil {
+		return err
+	}
+
+	selected, err := resolveBooks(ids, refs)
+	if err != nil {
+		return err
+	}
+	// полный ID книги не из избранного resolveBooks тоже принимает
+	for _, s := range selected {
+		if s.num < 0 {
+			return fmt.Errorf("%s: %w", s.id, favorites.ErrNotFound)
+		}
+	}
+
+	if len(selected) > 1 {
+		return r.forEachBook(ctx, "Reservation", selected, r.client.Reserve)
+	}
+
+	if err = r.client.Reserve(ctx, selected[0].id); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nBook successfully reserved!\n")
+
+	return nil
+}
+
+// exportFavorites сохраняет избранное в файл; формат выбирается по расширению
+func (r *Requester) exportFavorites(_ context.Context) error {
+	_, list, _, err := r.loadFavorites()
+	if err != nil {
+		return err
+	}
+
+	path, err := r.in.ExportPath()
+	if err != nil {
+		return err
+	}
+
+	format, err := output.FormatForFile(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = output.Render(file, format, r.favoritesTable(list, true), list)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\n%d favorites exported to %s\n", len(list), path)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package requesters
+
+import (
+	"encoding/base64"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	myCache "github.com/nikitalystsev/BookSmart-tech-ui/pkg/cache"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/favorites"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFavoritesDoNotReplaceViewedBooks(t *testing.T) {
+	apiClient, err := client.NewClient("http://127.0.0.1:1", client.TransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"` + uuid.NewString() + `"}`))
+	apiClient.RestoreTokens(dto.ReaderTokensDTO{AccessToken: "header." + payload + ".signature"}, time.Now())
+
+	r := &Requester{
+		client:    apiClient,
+		cache:     myCache.NewCache(),
+		favorites: favorites.NewFileStore(filepath.Join(t.TempDir(), "favorites.json")),
+	}
+
+	common := &jsonmodels.BookModel{ID: bookA, Title: "War and Peace", Author: "Tolstoy", Rarity: "Common"}
+	r.cache.Set(bookModelsKey, map[uuid.UUID]*jsonmodels.BookModel{bookA: common})
+
+	owner, err := r.favoritesOwner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []favorites.Favorite{
+		{BookID: bookA, Title: "War and Peace", Author: "Tolstoy"},
+		{BookID: bookB, Title: "Anna Karenina", Author: "Tolstoy"},
+	} {
+		if err = r.favorites.Add(owner, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, _, err = r.loadFavorites(); err != nil {
+		t.Fatal(err)
+	}
+
+	if book, ok := r.viewedBook(bookA); !ok || book.Rarity != "Common" {
+		t.Errorf("viewedBook(viewed) = %+v, %v; want the fully fetched book", book, ok)
+	}
+	if book, ok := r.viewedBook(bookB); ok {
+		t.Errorf("viewedBook(favorite only) = %+v, want not found", book)
+	}
+	if title := r.bookTitle(bookB); title != "Anna Karenina" {
+		t.Errorf("bookTitle(favorite only) = %q, want %q", title, "Anna Karenina")
+	}
+}
@@ -1,17 +1,13 @@
 package requesters
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
-	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
 	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
-	"log"
-	"net/http"
-	"time"
 )
 
 const libCardMenu = `Library card menu:
@@ -21,7 +17,7 @@ const libCardMenu = `Library card menu:
 	0 -- go to main menu
 `
 
-func (r *Requester) ProcessLibCardActions() error {
+func (r *Requester) ProcessLibCardActions(ctx context.Context) error {
 	var (
 		menuItem int
 		err      error
@@ -29,22 +25,22 @@ func (r *Requester) ProcessLibCardActions() error {
 	for {
 		fmt.Printf("\n\n%s", libCardMenu)
 
-		if menuItem, err = input.MenuItem(); err != nil {
+		if menuItem, err = r.in.MenuItem(); err != nil {
 			fmt.Printf("\n\n%s\n", err.Error())
 			continue
 		}
 
 		switch menuItem {
 		case 1:
-			if err = r.CreateLibCard(); err != nil {
+			if err = interruptible(ctx, r.CreateLibCard); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 2:
-			if err = r.UpdateLibCard(); err != nil {
+			if err = interruptible(ctx, r.UpdateLibCard); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 3:
-			if err = r.ViewLibCard(); err != nil {
+			if err = interruptible(ctx, r.ViewLibCard); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 0:
@@ -52,119 +48,47 @@ func (r *Requester) ProcessLibCardActions() error {
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
-	}
-}
-
-func (r *Requester) CreateLibCard() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
 
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/api/lib-cards",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Timeout: 10 * time.Second,
+		// сессия закончилась - выходим, меню читателя вернет на вход
+		if errors.Is(err, client.ErrSessionExpired) {
+			return nil
+		}
 	}
+}
 
-	response, err := SendRequest(request)
-	if err != nil {
+func (r *Requester) CreateLibCard(ctx context.Context) error {
+	if err := r.client.CreateLibCard(ctx); err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusCreated {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nSuccessfully created library card!\n")
 
 	return nil
 }
 
-func (r *Requester) UpdateLibCard() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
-
-	request := HTTPRequest{
-		Method: http.MethodPut,
-		URL:    r.baseURL + "/api/lib-cards",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
+func (r *Requester) UpdateLibCard(ctx context.Context) error {
+	if err := r.client.UpdateLibCard(ctx); err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nSuccessfully updated library card!\n")
 
 	return nil
 }
 
-func (r *Requester) ViewLibCard() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
-
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/api/lib-cards",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
+func (r *Requester) ViewLibCard(ctx context.Context) error {
+	libCard, err := r.client.GetLibCard(ctx)
 	if err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
-	var libCard *jsonmodels.LibCardModel
-	err = json.Unmarshal(response.Body, &libCard)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	printLibCard(libCard)
+	r.printLibCard(libCard)
 
 	return nil
 
 }
 
-func printLibCard(libCard *jsonmodels.LibCardModel) {
+func (r *Requester) printLibCard(libCard *jsonmodels.LibCardModel) {
 	t := table.NewWriter()
 	t.SetTitle("Library card")
 	t.SetStyle(table.StyleBold)
@@ -182,5 +106,5 @@ func printLibCard(libCard *jsonmodels.LibCardModel) {
 	t.AppendRow(table.Row{"Issue date", issueDateStr})
 	t.AppendRow(table.Row{"Status", statusStr})
 
-	fmt.Println(t.Render())
+	r.render(t, libCard)
 }
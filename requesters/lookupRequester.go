@@ -0,0 +1,104 @@
+package requesters
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"sync"
+)
+
+// maxBookLookups - сколько книг запрашивается у API одновременно
+const maxBookLookups = 8
+
+// parallel вызывает fn для индексов от 0 до n-1, не больше limit вызовов
+// одновременно. После отмены ctx новые вызовы не начинаются
+func parallel(ctx context.Context, n, limit int, fn func(ctx context.Context, i int)) {
+	var (
+		wg   sync.WaitGroup
+		slot = make(chan struct{}, limit)
+	)
+
+	for i := 0; i < n; i++ {
+		select {
+		case slot <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slot
+				wg.Done()
+			}()
+			fn(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// lookupBooks возвращает книги по ID. Уже запрошенные и просмотренные в
+// каталоге книги берутся из памяти, остальные запрашиваются параллельно.
+// Удаленной книги (404) в результате нет, и повторно она не запрашивается;
+// книги, которые не удалось получить из-за других ошибок, тоже пропускаются
+func (r *Requester) lookupBooks(ctx context.Context, ids []uuid.UUID) map[uuid.UUID]*jsonmodels.BookModel {
+	books := make(map[uuid.UUID]*jsonmodels.BookModel, len(ids))
+
+	var (
+		missing []uuid.UUID
+		pending = make(map[uuid.UUID]bool)
+	)
+
+	r.booksMu.Lock()
+	for _, id := range ids {
+		if _, ok := books[id]; ok || pending[id] {
+			continue
+		}
+		if book, ok := r.knownBooks[id]; ok {
+			if book != nil {
+				books[id] = book
+			}
+			continue
+		}
+		if book, ok := r.viewedBook(id); ok {
+			books[id] = book
+			continue
+		}
+		missing = append(missing, id)
+		pending[id] = true
+	}
+	r.booksMu.Unlock()
+
+	parallel(ctx, len(missing), maxBookLookups, func(ctx context.Context, i int) {
+		book, err := r.client.GetBook(ctx, missing[i])
+		if err != nil && !client.IsNotFound(err) {
+			return
+		}
+
+		r.booksMu.Lock()
+		defer r.booksMu.Unlock()
+
+		r.knownBooks[missing[i]] = book
+		if book != nil {
+			books[missing[i]] = book
+		}
+	})
+
+	return books
+}
+
+// knownBook возвращает уже известную книгу без запроса к API
+func (r *Requester) knownBook(id uuid.UUID) (*jsonmodels.BookModel, bool) {
+	r.booksMu.Lock()
+	book, ok := r.knownBooks[id]
+	r.booksMu.Unlock()
+
+	if ok && book != nil {
+		return book, true
+	}
+
+	return r.viewedBook(id)
+}
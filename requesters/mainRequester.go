@@ -1,10 +1,24 @@
 package requesters
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/nikitalystsev/BookSmart-tech-ui/config"
 	"github.com/nikitalystsev/BookSmart-tech-ui/input"
 	myCache "github.com/nikitalystsev/BookSmart-tech-ui/pkg/cache"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/favorites"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/profile"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"golang.org/x/term"
 	"os"
+	"os/signal"
+	"sync"
 	"time"
 )
 
@@ -13,34 +27,187 @@ const mainMenu = `Main menu:
 	2 -- sign in as reader
 	3 -- sign in as administrator
 	4 -- view books catalog
+	5 -- resume saved session
+	6 -- forget saved session
+	7 -- profiles
 	0 -- exit program
 `
 
 type Requester struct {
+	in              *input.Input
 	cache           myCache.ICache
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
-	baseURL         string
+	refreshMargin   time.Duration
+	refreshWarning  time.Duration
+	client          *client.Client
+
+	// клиент пересоздается при смене профиля с теми же настройками
+	baseURL    string
+	transport  client.TransportConfig
+	clientOpts []client.Option
+
+	// sessions - хранилище сессии текущего профиля, nil если оно выключено;
+	// role - под какой ролью выполнен текущий вход. Их читает saveSession на
+	// горутине обновления токенов, поэтому меняются они только под sessionMu
+	sessionStore      bool
+	sessionFile       string
+	sessionPassphrase string
+	sessionMu         sync.RWMutex
+	sessions          session.ISessionStore
+	profileSessions   map[string]session.ISessionStore
+	role              string
+
+	profiles profile.IProfileStore
+	profile  *profile.Profile
+
+	// favorites - локальный список избранного, nil если его негде хранить
+	favorites favorites.IFavoritesStore
+
+	// knownBooks - книги, уже запрошенные по ID (nil - книга удалена)
+	booksMu    sync.Mutex
+	knownBooks map[uuid.UUID]*jsonmodels.BookModel
+
+	// knownReaders - читатели, найденные по номеру телефона
+	knownReaders map[uuid.UUID]*jsonmodels.ReaderModel
+
+	phone    string
+	password string
+
+	output   output.Format
+	pageSize uint
+
+	// напоминания о сроках возврата: за сколько дней предупреждать, как
+	// часто проверять и когда проверяли последний раз; color - выделять цветом
+	dueSoonDays      uint
+	reminderInterval time.Duration
+	remindedAt       time.Time
+	color            bool
+
+	// lastResult - данные последнего вывода; в пакетном режиме их можно
+	// сохранить в переменную и подставить в следующие команды
+	lastResult interface{}
 }
 
-func NewRequester(
-	accessTokenTTL,
-	refreshTokenTTL time.Duration,
-	port string,
-) *Requester {
-	return &Requester{
-		cache:           myCache.NewCache(),
-		accessTokenTTL:  accessTokenTTL,
-		refreshTokenTTL: refreshTokenTTL,
-		baseURL:         "http://localhost:" + port,
+// NewRequester - прежний конструктор: настройки по умолчанию с заданными
+// временем жизни токенов и портом локального API, ввод из stdin. Новый код
+// использует NewRequesterFromConfig
+func NewRequester(accessTokenTTL, refreshTokenTTL time.Duration, port string) *Requester {
+	cfg := config.Default()
+	cfg.AccessTokenTTL = accessTokenTTL
+	cfg.RefreshTokenTTL = refreshTokenTTL
+	cfg.API.Port = port
+
+	r, err := NewRequesterFromConfig(cfg, input.NewStdin())
+	if err != nil {
+		// с настройками по умолчанию ошибок не бывает: нет ни CA-файла, ни профиля
+		panic(err)
 	}
+
+	return r
 }
 
+// NewRequesterFromConfig создает requester по настройкам cfg с вводом in
+func NewRequesterFromConfig(cfg config.Config, in *input.Input, opts ...client.Option) (*Requester, error) {
+	r := &Requester{
+		in:                in,
+		cache:             myCache.NewCache(),
+		accessTokenTTL:    cfg.AccessTokenTTL,
+		refreshTokenTTL:   cfg.RefreshTokenTTL,
+		refreshMargin:     cfg.RefreshMargin,
+		refreshWarning:    cfg.RefreshWarning,
+		baseURL:           cfg.API.BaseURL(),
+		transport:         cfg.Transport,
+		sessionStore:      cfg.SessionStore,
+		sessionFile:       cfg.SessionFile,
+		sessionPassphrase: cfg.SessionPassphrase,
+		profileSessions:   make(map[string]session.ISessionStore),
+		knownBooks:        make(map[uuid.UUID]*jsonmodels.BookModel),
+		knownReaders:      make(map[uuid.UUID]*jsonmodels.ReaderModel),
+		phone:             cfg.Phone,
+		password:          cfg.Password,
+		output:            cfg.Output,
+		pageSize:          cfg.PageSize,
+		dueSoonDays:       cfg.DueSoonDays,
+		reminderInterval:  cfg.ReminderInterval,
+		color:             term.IsTerminal(int(os.Stdout.Fd())) && os.Getenv("NO_COLOR") == "",
+	}
+
+	r.clientOpts = append([]client.Option{
+		client.WithRetryPolicy(cfg.Retry),
+		client.WithTokensListener(r.saveSession),
+	}, opts...)
+
+	if cfg.FavoritesFile != "" {
+		r.favorites = favorites.NewFileStore(cfg.FavoritesFile)
+	}
+	if cfg.ProfilesFile != "" {
+		r.profiles = profile.NewFileStore(cfg.ProfilesFile)
+	}
+	if cfg.Profile != "" {
+		if r.profiles == nil {
+			return nil, errProfilesUnavailable
+		}
+		p, err := r.profiles.Get(cfg.Profile)
+		if err != nil {
+			return nil, err
+		}
+		r.profile = &p
+	}
+
+	apiClient, err := r.newClient()
+	if err != nil {
+		return nil, err
+	}
+	r.client = apiClient
+
+	return r, nil
+}
+
+// newClient создает клиент для адреса API текущего профиля
+func (r *Requester) newClient() (*client.Client, error) {
+	baseURL := r.baseURL
+	if r.profile != nil && r.profile.APIURL != "" {
+		baseURL = r.profile.APIURL
+	}
+
+	return client.NewClient(baseURL, r.transport, r.clientOpts...)
+}
+
+var errOperationCancelled = errors.New("operation cancelled")
+
+// Run - прежняя точка входа: RunContext без внешнего контекста
 func (r *Requester) Run() {
+	r.RunContext(context.Background())
+}
+
+// RunContext показывает главное меню до выхода из программы
+func (r *Requester) RunContext(ctx context.Context) {
+	// Ctrl+C не завершает программу: выполняемая операция отменяется (см.
+	// interruptible), а запрос к пользователю работает как Ctrl+D - "назад"
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	r.in.HandleInterrupts(interrupts)
+	defer r.in.HandleInterrupts(nil)
+
+	if err := r.openSessionStore(); err != nil {
+		fmt.Printf("\n\n%s\n", err.Error())
+	}
+	if r.profile != nil {
+		if err := r.SwitchProfile(ctx, *r.profile); err != nil {
+			fmt.Printf("\n\n%s\n", err.Error())
+		}
+	} else if err := r.ResumeSession(ctx); err != nil && err != session.ErrNoSession {
+		// о просто отсутствующей сессии не сообщаем, а об истекшей - да
+		fmt.Printf("\n\n%s\n", err.Error())
+	}
+
 	for {
-		fmt.Printf("\n\n%s", mainMenu)
+		fmt.Printf("\n\n%s\n%s", r.profileInfo(), mainMenu)
 
-		menuItem, err := input.MenuItem()
+		menuItem, err := r.in.MenuItem()
 		if err != nil {
 			fmt.Printf("\n\n%s\n", err.Error())
 			continue
@@ -48,25 +215,74 @@ func (r *Requester) Run() {
 
 		switch menuItem {
 		case 1:
-			if err = r.SignUp(); err != nil {
+			if err = interruptible(ctx, r.SignUp); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 2:
-			if err = r.ProcessReaderActions(); err != nil {
+			if err = r.ProcessReaderActions(ctx); err != nil {
 				continue
 			}
 		case 3:
-			if err = r.ProcessAdminActions(); err != nil {
+			if err = r.ProcessAdminActions(ctx); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 4:
-			if err = r.ProcessBookCatalogActions(); err != nil {
+			if err = r.ProcessBookCatalogActions(ctx); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 5:
+			if err = r.ResumeSession(ctx); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 6:
+			if err = r.ForgetSession(); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 7:
+			if err = r.ProcessProfileActions(ctx); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 0:
-			os.Exit(0)
+			return
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
 	}
 }
+
+// HealthCheck проверяет доступность API перед началом работы
+func (r *Requester) HealthCheck(ctx context.Context) error {
+	if err := r.client.Ping(ctx); err != nil {
+		return r.unavailableError(err)
+	}
+
+	return nil
+}
+
+func (r *Requester) unavailableError(err error) error {
+	return fmt.Errorf("BookSmart API at %s is unavailable: %w", r.client.BaseURL(), err)
+}
+
+// render выводит таблицу t или данные data в выбранном формате
+func (r *Requester) render(t table.Writer, data interface{}) {
+	r.lastResult = data
+
+	if err := output.Render(os.Stdout, r.output, t, data); err != nil {
+		fmt.Printf("\n\nerror rendering output: %v\n", err)
+	}
+}
+
+// interruptible выполняет операцию в контексте, который отменяется по Ctrl+C.
+// Прерванная операция, как и Ctrl+D в ее запросах, возвращает в текущее меню
+// вместо завершения программы
+func interruptible(ctx context.Context, operation func(context.Context) error) error {
+	opCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	err := operation(opCtx)
+	if err != nil && opCtx.Err() != nil && ctx.Err() == nil || errors.Is(err, input.ErrBack) {
+		return errOperationCancelled
+	}
+
+	return err
+}
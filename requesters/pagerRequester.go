@@ -0,0 +1,224 @@
+package requesters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nikitalystsev/BookSmart-tech-ui/config"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+)
+
+// bookSearch - текущий поиск в каталоге: запрос, номер показанной страницы
+// (-1 - еще ничего не показано) и число найденных книг, если оно уже известно
+type bookSearch struct {
+	query search.Query
+	page  int
+	total int
+}
+
+func newBookSearch(query search.Query) bookSearch {
+	return bookSearch{query: query, page: -1, total: -1}
+}
+
+func (r *Requester) viewFirstPage(ctx context.Context) error {
+	var query search.Query
+
+	isWithParams, err := r.in.IsWithParams()
+	if err != nil {
+		return err
+	}
+
+	if isWithParams {
+		if query, err = r.in.Params(); err != nil {
+			return err
+		}
+	}
+
+	r.cache.Set(booksKey, make([]uuid.UUID, 0))
+	r.cache.Set(bookModelsKey, make(map[uuid.UUID]*jsonmodels.BookModel))
+
+	return r.viewPage(ctx, newBookSearch(query), 0)
+}
+
+func (r *Requester) viewNextPage(ctx context.Context) error {
+	current, err := r.currentSearch()
+	if err != nil {
+		return err
+	}
+
+	return r.viewPage(ctx, current, current.page+1)
+}
+
+func (r *Requester) viewPrevPage(ctx context.Context) error {
+	current, err := r.currentSearch()
+	if err != nil {
+		return err
+	}
+
+	if current.page <= 0 {
+		return errors.New("this is the first page")
+	}
+
+	return r.viewPage(ctx, current, current.page-1)
+}
+
+// viewStartPage возвращает к первой странице, сохраняя фильтры поиска
+func (r *Requester) viewStartPage(ctx context.Context) error {
+	current, err := r.currentSearch()
+	if err != nil {
+		return err
+	}
+
+	return r.viewPage(ctx, current, 0)
+}
+
+func (r *Requester) jumpToPage(ctx context.Context) error {
+	current, err := r.currentSearch()
+	if err != nil {
+		return err
+	}
+
+	page, err := r.in.PageNumber()
+	if err != nil {
+		return err
+	}
+
+	return r.viewPage(ctx, current, int(page)-1)
+}
+
+// viewLastPage показывает последнюю страницу; если число книг еще неизвестно,
+// оно определяется пробными запросами
+func (r *Requester) viewLastPage(ctx context.Context) error {
+	current, err := r.currentSearch()
+	if err != nil {
+		return err
+	}
+
+	if current.total < 0 {
+		if current.total, err = search.Count(ctx, r.client.ListBooks, current.query); err != nil {
+			return err
+		}
+	}
+	if current.total == 0 {
+		return errors.New("no books found")
+	}
+
+	return r.viewPage(ctx, current, r.lastPage(current.total))
+}
+
+// changePageSize меняет размер страницы так, чтобы первая показанная книга
+// осталась на экране
+func (r *Requester) changePageSize(ctx context.Context) error {
+	current, err := r.currentSearch()
+	if err != nil {
+		return err
+	}
+
+	size, err := r.in.PageSize(config.MaxPageSize)
+	if err != nil {
+		return err
+	}
+
+	offset := current.page * int(r.pageSize)
+	r.pageSize = size
+
+	if current.page < 0 {
+		fmt.Printf("\n\nPage size set to %d\n", size)
+		return nil
+	}
+
+	return r.viewPage(ctx, current, offset/int(size))
+}
+
+func (r *Requester) currentSearch() (bookSearch, error) {
+	var current bookSearch
+	if err := r.cache.Get(bookParamsKey, &current); err != nil {
+		return bookSearch{}, err
+	}
+
+	return current, nil
+}
+
+func (r *Requester) lastPage(total int) int {
+	return (total - 1) / int(r.pageSize)
+}
+
+// viewPage выводит страницу page по текущему поиску и запоминает
+// идентификаторы показанных книг по их сквозным номерам
+func (r *Requester) viewPage(ctx context.Context, current bookSearch, page int) error {
+	if page < 0 {
+		return errors.New("page number out of range")
+	}
+	if current.total >= 0 && page > r.lastPage(current.total) && page > 0 {
+		return fmt.Errorf("there are only %d pages", r.lastPage(current.total)+1)
+	}
+
+	offset := page * int(r.pageSize)
+	books, err := search.Page(ctx, r.client.ListBooks, current.query, offset, r.pageSize)
+	// сервер отвечает 404 на страницу за концом каталога
+	if page > 0 && (client.IsNotFound(err) || err == nil && len(books) == 0) {
+		err = errors.New("no more books")
+	}
+	if err != nil {
+		return err
+	}
+
+	if uint(len(books)) < r.pageSize {
+		current.total = offset + len(books)
+	}
+	current.page = page
+	r.cache.Set(bookParamsKey, current)
+
+	if err = r.rememberBooks(offset, books); err != nil {
+		return err
+	}
+
+	r.printBooks(books, offset)
+	r.printPageStatus(current, offset, len(books))
+
+	return nil
+}
+
+func (r *Requester) rememberBooks(offset int, books []*jsonmodels.BookModel) error {
+	var bookPagesID []uuid.UUID
+	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
+		return err
+	}
+
+	if need := offset + len(books); need > len(bookPagesID) {
+		bookPagesID = append(bookPagesID, make([]uuid.UUID, need-len(bookPagesID))...)
+	}
+	var models map[uuid.UUID]*jsonmodels.BookModel
+	if err := r.cache.Get(bookModelsKey, &models); err != nil {
+		return err
+	}
+
+	for i, book := range books {
+		bookPagesID[offset+i] = book.ID
+		models[book.ID] = book
+	}
+	r.cache.Set(booksKey, bookPagesID)
+
+	return nil
+}
+
+func (r *Requester) printPageStatus(current bookSearch, offset, shown int) {
+	status := fmt.Sprintf("Page %d", current.page+1)
+	if current.total >= 0 {
+		status += fmt.Sprintf(" of %d", r.lastPage(current.total)+1)
+	}
+
+	if shown == 0 {
+		fmt.Printf("%s, no books found\n", status)
+		return
+	}
+
+	status += fmt.Sprintf(", showing items %d–%d", offset+1, offset+shown)
+	if current.total >= 0 {
+		status += fmt.Sprintf(" of %d", current.total)
+	}
+	fmt.Println(status)
+}
@@ -0,0 +1,232 @@
+package requesters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/profile"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	"os"
+)
+
+const profilesMenu = `Profiles menu:
+	1 -- list profiles
+	2 -- add profile
+	3 -- switch to profile
+	4 -- delete profile
+	0 -- go to main menu
+`
+
+var errProfilesUnavailable = errors.New("profiles are unavailable: user config directory is not set")
+
+func (r *Requester) ProcessProfileActions(ctx context.Context) error {
+	if r.profiles == nil {
+		return errProfilesUnavailable
+	}
+
+	for {
+		fmt.Printf("\n\n%s", profilesMenu)
+
+		menuItem, err := r.in.MenuItem()
+		if err != nil {
+			fmt.Printf("\n\n%s\n", err.Error())
+			continue
+		}
+
+		switch menuItem {
+		case 1:
+			if err = r.ListProfiles(); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 2:
+			if err = r.AddProfile(); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 3:
+			name, err := r.in.ProfileName()
+			if errors.Is(err, input.ErrBack) {
+				continue
+			}
+			if err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+				continue
+			}
+			p, err := r.profiles.Get(name)
+			if err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+				continue
+			}
+			// после выхода из аккаунта профиля возвращаемся в главное меню
+			if err = r.SwitchProfile(ctx, p); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+			return nil
+		case 4:
+			if err = r.DeleteProfile(); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 0:
+			return nil
+		default:
+			fmt.Printf("\n\nWrong menu item!\n")
+		}
+	}
+}
+
+func (r *Requester) ListProfiles() error {
+	profiles, err := r.profiles.List()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		fmt.Printf("\n\nNo profiles yet\n")
+		return nil
+	}
+
+	t := table.NewWriter()
+	t.SetTitle("Profiles")
+	t.SetStyle(table.StyleBold)
+	t.Style().Format.Header = text.FormatTitle
+	t.AppendHeader(table.Row{"", "Name", "API URL", "Role", "Phone Number", "Password Env"})
+
+	views := make([]profileView, len(profiles))
+	for i, p := range profiles {
+		current := ""
+		if r.profile != nil && r.profile.Name == p.Name {
+			current = "*"
+		}
+		if p.APIURL == "" {
+			p.APIURL = r.baseURL
+		}
+		t.AppendRow(table.Row{current, p.Name, p.APIURL, p.Role, p.PhoneNumber, p.PasswordEnv})
+		views[i] = profileView{Profile: p, Current: current != ""}
+	}
+
+	r.render(t, views)
+
+	return nil
+}
+
+// profileView - профиль с отметкой текущего для машиночитаемых форматов
+type profileView struct {
+	profile.Profile
+	Current bool `json:"current"`
+}
+
+func (r *Requester) AddProfile() error {
+	p, err := r.in.ProfileParams()
+	if err != nil {
+		return err
+	}
+
+	if err = r.profiles.Put(p); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nProfile %s saved!\n", p.Name)
+
+	return nil
+}
+
+func (r *Requester) DeleteProfile() error {
+	name, err := r.in.ProfileName()
+	if err != nil {
+		return err
+	}
+
+	if err = r.profiles.Delete(name); err != nil {
+		return err
+	}
+
+	// вместе с профилем забываем и его токены
+	if err = r.sessionStoreFor(name).Delete(); err != nil {
+		return err
+	}
+	delete(r.profileSessions, name)
+
+	if r.profile != nil && r.profile.Name == name {
+		r.profile = nil
+		r.setSessionStore(r.sessionStoreFor(""))
+		if r.client, err = r.newClient(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n\nProfile %s deleted!\n", name)
+
+	return nil
+}
+
+// SwitchProfile делает профиль p текущим и входит под ним: по сохраненным
+// токенам профиля, а если их нет или они истекли - по его учетным данным
+func (r *Requester) SwitchProfile(ctx context.Context, p profile.Profile) error {
+	apiClient := r.client
+	r.client.SignOut()
+
+	r.profile = &p
+	r.setSessionStore(r.sessionStoreFor(p.Name))
+
+	var err error
+	if r.client, err = r.newClient(); err != nil {
+		r.client = apiClient
+		return err
+	}
+
+	fmt.Printf("\n\nSwitched to profile %s\n", p.Name)
+
+	role, err := r.restoreSession(ctx)
+	if errors.Is(err, session.ErrNoSession) {
+		if err != session.ErrNoSession {
+			fmt.Printf("\n\n%s\n", err.Error())
+		}
+		role = p.Role
+		err = interruptible(ctx, r.signInProfile)
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.enterSession(ctx, role)
+}
+
+// signInProfile входит по учетным данным текущего профиля
+func (r *Requester) signInProfile(ctx context.Context) error {
+	signIn := dto.ReaderSignInDTO{PhoneNumber: r.profile.PhoneNumber}
+	if r.profile.PasswordEnv != "" {
+		signIn.Password = os.Getenv(r.profile.PasswordEnv)
+	}
+
+	var err error
+	if signIn.Password == "" {
+		fmt.Printf("Signing in as %s\n", signIn.PhoneNumber)
+		if signIn.Password, err = r.in.Password(); err != nil {
+			return err
+		}
+	}
+
+	r.setRole(r.profile.Role)
+	if r.role == session.RoleAdmin {
+		_, err = r.client.SignInAsAdmin(ctx, signIn)
+	} else {
+		_, err = r.client.SignIn(ctx, signIn)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nAuthentication successful!\n")
+
+	return nil
+}
+
+func (r *Requester) profileInfo() string {
+	if r.profile == nil {
+		return fmt.Sprintf("API: %s", r.client.BaseURL())
+	}
+
+	return fmt.Sprintf("Profile: %s (%s, %s)", r.profile.Name, r.profile.Role, r.client.BaseURL())
+}
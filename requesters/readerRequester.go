@@ -1,12 +1,11 @@
 package requesters
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"github.com/nikitalystsev/BookSmart-tech-ui/input"
-	"net/http"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
 	"time"
 )
 
@@ -14,184 +13,210 @@ const readerMainMenu = `Main menu:
 	1 -- go to books catalog 
 	2 -- go to library card
 	3 -- go to your reservations
+	4 -- go to your favorites
+	5 -- extend now (due reservations)
 	0 -- log out
 `
 
-const tokensKey = "tokens"
+func (r *Requester) ProcessReaderActions(ctx context.Context) error {
+	if err := interruptible(ctx, r.SignIn); err != nil {
+		fmt.Printf("\n\n%s\n", err.Error())
+		return err
+	}
+
+	return r.readerSession(ctx)
+}
 
-func (r *Requester) ProcessReaderActions() error {
+// readerSession - меню читателя, в которое он попадает после входа
+func (r *Requester) readerSession(ctx context.Context) error {
 	var (
 		menuItem int
 		err      error
 	)
-	stopRefresh := make(chan struct{})
 
-	if err = r.SignIn(stopRefresh); err != nil {
-		fmt.Printf("\n\n%s\n", err.Error())
-		return err
-	}
+	r.cache.Clear()
+	r.remindedAt = time.Time{}
+
+	// токены обновляются, пока читатель не выйдет из аккаунта
+	sessionCtx, stopRefresh := context.WithCancel(ctx)
+	defer stopRefresh()
+
+	go r.Refreshing(sessionCtx)
 
 	for {
-		fmt.Printf("\n\n%s", readerMainMenu)
+		r.remindIfDue(ctx)
+		fmt.Printf("\n\n%s\n%s", r.sessionInfo(), readerMainMenu)
 
-		if menuItem, err = input.MenuItem(); err != nil {
+		if menuItem, err = r.in.MenuItem(); err != nil {
 			fmt.Printf("\n\n%s\n", err.Error())
 			continue
 		}
 
 		switch menuItem {
 		case 1:
-			if err = r.ProcessBookCatalogActions(); err != nil {
+			if err = r.ProcessBookCatalogActions(ctx); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 2:
-			if err = r.ProcessLibCardActions(); err != nil {
+			if err = r.ProcessLibCardActions(ctx); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 3:
-			if err = r.ProcessReservationsActions(); err != nil {
+			if err = r.ProcessReservationsActions(ctx); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 4:
+			if err = r.ProcessFavoritesActions(ctx); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 5:
+			if err = interruptible(ctx, r.extendNow); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 0:
-			close(stopRefresh)
+			stopRefresh()
+			r.logOut()
 			r.cache.Clear()
 			fmt.Println("\n\nyou have successfully log out")
 			return nil
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
+
+		// refresh-токен отклонен сервером - возвращаемся к входу
+		if !r.client.IsAuthenticated() {
+			stopRefresh()
+			r.cache.Clear()
+			return nil
+		}
 	}
 }
 
-func (r *Requester) SignUp() error {
-	readerSignUpDTO, err := input.SignUpParams()
+func (r *Requester) SignUp(ctx context.Context) error {
+	readerSignUpDTO, err := r.in.SignUpParams()
 	if err != nil {
 		return err
 	}
 
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/auth/sign-up",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		Body:    readerSignUpDTO,
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
+	if err = r.client.SignUp(ctx, readerSignUpDTO); err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusCreated {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
-
 	fmt.Printf("\n\nRegistration completed successfully!\n")
 
 	return nil
 }
 
-func (r *Requester) SignIn(stopRefresh <-chan struct{}) error {
-	readerSignInDTO, err := input.SignInParams()
+func (r *Requester) SignIn(ctx context.Context) error {
+	readerSignInDTO, err := r.in.SignInParams()
 	if err != nil {
 		return err
 	}
 
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/auth/sign-in",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		Body:    readerSignInDTO,
-		Timeout: 10 * time.Second,
-	}
-
-	response, err := SendRequest(request)
-	if err != nil {
+	r.setRole(session.RoleReader)
+	if _, err = r.client.SignIn(ctx, readerSignInDTO); err != nil {
 		return err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
+	fmt.Printf("\n\nAuthentication successful!\n")
 
-	var tokens dto.ReaderTokensDTO
-	if err = json.Unmarshal(response.Body, &tokens); err != nil {
+	return nil
+}
+
+func (r *Requester) Refresh(ctx context.Context) error {
+	if _, err := r.client.Refresh(ctx); err != nil {
 		return err
 	}
 
-	r.cache.Set(tokensKey, tokens)
-
-	fmt.Printf("\n\nAuthentication successful!\n")
-
-	go r.Refreshing(r.accessTokenTTL, stopRefresh)
+	//fmt.Printf("\n\nSuccessful refresh tokens!\n")
 
 	return nil
 }
 
-func (r *Requester) Refresh() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
-		return err
-	}
+const refreshRetryDelay = 10 * time.Second
 
-	request := HTTPRequest{
-		Method: http.MethodPost,
-		URL:    r.baseURL + "/auth/refresh",
-		Headers: map[string]string{
-			"Content-Type": "application/json",
-		},
-		Body:    tokens.RefreshToken,
-		Timeout: 10 * time.Second,
-	}
+// Refreshing обновляет токен доступа за refreshMargin до истечения его срока
+// (claim exp), пока не будет отменен ctx. Отмена ctx прерывает и уже начатый
+// запрос на обновление
+func (r *Requester) Refreshing(ctx context.Context) {
+	delay := r.nextRefreshIn()
+	warned := false
 
-	response, err := SendRequest(request)
-	if err != nil {
-		return err
-	}
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		err := r.Refresh(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Printf("\n\nerror refreshing tokens: %v\n", err)
+		}
+		if errors.Is(err, client.ErrSessionExpired) {
+			return
+		}
+
+		delay = r.nextRefreshIn()
+		if err == nil {
+			warned = false
+			continue
+		}
+		delay = min(delay, refreshRetryDelay)
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
+		// сервер выдает новый refresh-токен на полный срок при каждом обновлении,
+		// поэтому сессия подходит к концу, только если обновления не проходят
+		if left := r.sessionTimeLeft(); left < r.refreshWarning && !warned {
+			fmt.Printf("\n\nyour session expires in %s, please sign in again soon\n", formatLifetime(left))
+			warned = true
 		}
-		return errors.New(info)
 	}
+}
 
-	if err = json.Unmarshal(response.Body, &tokens); err != nil {
-		return err
+// nextRefreshIn возвращает время до планового обновления токенов
+func (r *Requester) nextRefreshIn() time.Duration {
+	return max(r.accessTimeLeft()-r.refreshMargin, time.Second)
+}
+
+// accessTimeLeft возвращает оставшийся срок действия токена доступа
+func (r *Requester) accessTimeLeft() time.Duration {
+	expiry, ok := client.AccessTokenExpiry(r.client.Tokens())
+	if !ok {
+		expiry = r.client.TokensIssuedAt().Add(r.accessTokenTTL)
 	}
 
-	r.cache.Set(tokensKey, tokens)
+	return time.Until(expiry)
+}
 
-	//fmt.Printf("\n\nSuccessful refresh tokens!\n")
+// sessionTimeLeft возвращает оставшийся срок действия refresh-токена. Токен
+// меняется при каждом обновлении, поэтому срок отсчитывается от получения текущей пары
+func (r *Requester) sessionTimeLeft() time.Duration {
+	return time.Until(r.client.TokensIssuedAt().Add(r.refreshTokenTTL))
+}
 
-	return nil
+func (r *Requester) sessionInfo() string {
+	return fmt.Sprintf(
+		"Access token expires in %s, session expires in %s",
+		formatLifetime(r.accessTimeLeft()),
+		formatLifetime(r.sessionTimeLeft()),
+	)
 }
 
-func (r *Requester) Refreshing(interval time.Duration, stopRefresh <-chan struct{}) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+// formatLifetime округляет срок до секунд, а длинные сроки выводит в днях
+func formatLifetime(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
 
-	for {
-		select {
-		case <-ticker.C:
-			if err := r.Refresh(); err != nil {
-				fmt.Printf("\n\nerror refreshing tokens: %v\n", err)
-			}
-		case <-stopRefresh:
-			return
-		}
+	const day = 24 * time.Hour
+	if d < day {
+		return d.Round(time.Second).String()
 	}
+
+	return fmt.Sprintf("%dd%dh", d/day, (d%day)/time.Hour)
 }
@@ -0,0 +1,200 @@
+package requesters
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"math"
+	"slices"
+	"time"
+)
+
+// reminder - бронь, о сроке возврата которой нужно напомнить; daysLeft
+// отрицательно для просроченной
+type reminder struct {
+	reservation *jsonmodels.ReservationModel
+	num         int
+	daysLeft    int
+}
+
+func (rm reminder) overdue() bool {
+	return search.Overdue(rm.reservation, time.Now())
+}
+
+// remindIfDue показывает напоминание о сроках возврата сразу после входа и
+// затем не чаще раза в reminderInterval - при возврате в главное меню.
+// Неудачная проверка тоже считается: пока API недоступен, ошибка выводится
+// раз в интервал, а не при каждом возврате в меню
+func (r *Requester) remindIfDue(ctx context.Context) {
+	if !r.remindedAt.IsZero() && time.Since(r.remindedAt) < r.reminderInterval {
+		return
+	}
+	r.remindedAt = time.Now()
+
+	err := interruptible(ctx, func(ctx context.Context) error {
+		reminders, err := r.loadReminders(ctx)
+		if err != nil {
+			return err
+		}
+
+		r.printReminders(ctx, reminders)
+
+		return nil
+	})
+	// у читателя может не быть ни одной брони
+	if err != nil && !client.IsNotFound(err) {
+		fmt.Printf("\n\ncould not check your reservations: %v\n", err)
+	}
+}
+
+// loadReminders загружает брони читателя и отбирает просроченные и те, срок
+// возврата которых наступает в ближайшие dueSoonDays дней. Список броней
+// сохраняется, чтобы по номеру из напоминания можно было выбрать бронь
+func (r *Requester) loadReminders(ctx context.Context) ([]reminder, error) {
+	reservations, err := r.client.ListReservations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(reservationsKey, reservations)
+
+	return dueReminders(reservations, r.dueSoonDays, time.Now()), nil
+}
+
+// dueReminders отбирает незакрытые брони с истекшим или близким сроком
+// возврата: сначала просроченные, затем по сроку
+func dueReminders(reservations []*jsonmodels.ReservationModel, days uint, now time.Time) []reminder {
+	var reminders []reminder
+	for i, reservation := range reservations {
+		if reservation.State == reservationClosed {
+			continue
+		}
+
+		rm := reminder{reservation: reservation, num: i, daysLeft: daysBetween(now, reservation.ReturnDate)}
+		if rm.overdue() || rm.daysLeft <= int(days) {
+			reminders = append(reminders, rm)
+		}
+	}
+
+	slices.SortStableFunc(reminders, func(a, b reminder) int {
+		return a.reservation.ReturnDate.Compare(b.reservation.ReturnDate)
+	})
+
+	return reminders
+}
+
+// daysBetween возвращает число календарных дней от from до to
+func daysBetween(from, to time.Time) int {
+	day := func(t time.Time) time.Time {
+		t = t.In(time.Local)
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+
+	return int(math.Round(day(to).Sub(day(from)).Hours() / 24))
+}
+
+func (rm reminder) status() string {
+	switch days := rm.daysLeft; {
+	case days < -1:
+		return fmt.Sprintf("overdue by %d days", -days)
+	case days == -1:
+		return "overdue by 1 day"
+	case days == 0 && rm.overdue():
+		return "overdue"
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "due tomorrow"
+	default:
+		return fmt.Sprintf("due in %d days", days)
+	}
+}
+
+// returnStatus описывает срок возврата по брони, например "due in 3 days"
+func returnStatus(reservation *jsonmodels.ReservationModel) string {
+	if reservation.State == reservationClosed {
+		return "closed"
+	}
+
+	return reminder{reservation: reservation, daysLeft: daysBetween(time.Now(), reservation.ReturnDate)}.status()
+}
+
+// printReminders выводит баннер: просроченные брони красным, близкие к сроку
+// желтым. Если какую-то из них можно продлить, подсказывает пункт меню
+func (r *Requester) printReminders(ctx context.Context, reminders []reminder) {
+	if len(reminders) == 0 {
+		return
+	}
+
+	books := r.reminderBooks(ctx, reminders)
+
+	fmt.Printf("\n\nReturn reminders:\n")
+
+	extendable := false
+	for _, rm := range reminders {
+		line := fmt.Sprintf("\t№%d  %-8s  return by %s  %s",
+			rm.num, rm.reservation.State, formatDate(rm.reservation.ReturnDate), rm.status())
+		if book, ok := books[rm.reservation.BookID]; ok {
+			line += fmt.Sprintf("  %q by %s", book.Title, book.Author)
+		}
+
+		colors := text.Colors{text.FgYellow}
+		if rm.overdue() {
+			colors = text.Colors{text.FgRed, text.Bold}
+		}
+		if r.color {
+			line = colors.Sprint(line)
+		}
+		fmt.Println(line)
+
+		extendable = extendable || checkReservationAction(rm.reservation, books[rm.reservation.BookID], actionExtend) == nil
+	}
+
+	if extendable {
+		fmt.Printf("Choose \"extend now\" in the main menu to extend a reservation\n")
+	}
+}
+
+func (r *Requester) reminderBooks(ctx context.Context, reminders []reminder) map[uuid.UUID]*jsonmodels.BookModel {
+	reservations := make([]*jsonmodels.ReservationModel, len(reminders))
+	for i, rm := range reminders {
+		reservations[i] = rm.reservation
+	}
+
+	return r.lookupBooks(ctx, reservationBookIDs(reservations))
+}
+
+// extendNow продлевает бронь из напоминания. Если продлить можно только
+// одну, она выбирается сразу
+func (r *Requester) extendNow(ctx context.Context) error {
+	reminders, err := r.loadReminders(ctx)
+	if err != nil {
+		return err
+	}
+
+	books := r.reminderBooks(ctx, reminders)
+
+	var extendable []reminder
+	for _, rm := range reminders {
+		if checkReservationAction(rm.reservation, books[rm.reservation.BookID], actionExtend) == nil {
+			extendable = append(extendable, rm)
+		}
+	}
+
+	if len(extendable) == 0 {
+		fmt.Printf("\n\nNo due reservations can be extended\n")
+		return nil
+	}
+	if len(extendable) == 1 {
+		rm := extendable[0]
+		return r.confirmExtension(ctx, selection{id: rm.reservation.ID, num: rm.num})
+	}
+
+	r.printReminders(ctx, extendable)
+
+	return r.ExtendReservation(ctx)
+}
@@ -0,0 +1,41 @@
+package requesters
+
+import (
+	"context"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	myCache "github.com/nikitalystsev/BookSmart-tech-ui/pkg/cache"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRemindIfDueBacksOffAfterFailure(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	apiClient, err := client.NewClient(server.URL, client.DefaultTransportConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiClient.RestoreTokens(dto.ReaderTokensDTO{AccessToken: "access"}, time.Now())
+
+	r := &Requester{
+		client:           apiClient,
+		cache:            myCache.NewCache(),
+		reminderInterval: time.Hour,
+	}
+
+	for i := 0; i < 3; i++ {
+		r.remindIfDue(context.Background())
+	}
+
+	if calls != 1 {
+		t.Errorf("reservation requests = %d, want 1 per reminder interval", calls)
+	}
+}
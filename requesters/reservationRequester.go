@@ -1,50 +1,97 @@
 package requesters
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
-	"github.com/nikitalystsev/BookSmart-services/core/dto"
-	"github.com/nikitalystsev/BookSmart-tech-ui/input"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/output"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/search"
 	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
-	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
 const reservationsMenu = `Reservations menu:
-	1 -- view your reservations 
-	2 -- update your reservation
+	1 -- view your reservations
+	2 -- extend reservation
+	3 -- filter history
+	4 -- sort by return date
 	0 -- go to main menu
+
+Cancelling a reservation and returning a book are done at the library:
+the API has no such actions.
 `
 
-const reservationsKey = "reservations"
+const (
+	reservationsKey       = "reservations"
+	reservationFiltersKey = "reservationFilters"
+)
+
+// Состояния брони на сервере
+const (
+	reservationIssued   = "Issued"
+	reservationExtended = "Extended"
+	reservationExpired  = "Expired"
+	reservationClosed   = "Closed"
+)
+
+// reservationExtensionDays - на сколько дней сервер продлевает бронь
+const reservationExtensionDays = 7
+
+// bookRarityCommon - редкость книги, бронь на которую сервер продлевает
+const bookRarityCommon = "Common"
 
-func (r *Requester) ProcessReservationsActions() error {
+// Действия с бронью
+const (
+	actionExtend = "extend"
+)
+
+// reservationActions - что можно сделать с бронью в каждом состоянии.
+// Продлить можно только один раз и только бронь на обычную книгу
+var reservationActions = map[string][]string{
+	reservationIssued:   {actionExtend},
+	reservationExtended: nil,
+	reservationExpired:  nil,
+	reservationClosed:   nil,
+}
+
+func (r *Requester) ProcessReservationsActions(ctx context.Context) error {
 	var (
 		menuItem int
 		err      error
 	)
 
-	r.cache.Set(reservationsKey, make([]uuid.UUID, 0))
+	r.cache.Set(reservationsKey, make([]*jsonmodels.ReservationModel, 0))
+	r.cache.Set(reservationFiltersKey, search.ReservationQuery{})
 
 	for {
 		fmt.Printf("\n\n%s", reservationsMenu)
 
-		if menuItem, err = input.MenuItem(); err != nil {
+		if menuItem, err = r.in.MenuItem(); err != nil {
 			fmt.Printf("\n\n%s\n", err.Error())
 			continue
 		}
 
 		switch menuItem {
 		case 1:
-			if err = r.ViewReservations(); err != nil {
+			if err = interruptible(ctx, r.ViewReservations); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 2:
-			if err = r.UpdateReservation(); err != nil {
+			if err = interruptible(ctx, r.ExtendReservation); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 3:
+			if err = interruptible(ctx, r.filterReservations); err != nil {
+				fmt.Printf("\n\n%s\n", err.Error())
+			}
+		case 4:
+			if err = interruptible(ctx, r.sortReservationsByReturnDate); err != nil {
 				fmt.Printf("\n\n%s\n", err.Error())
 			}
 		case 0:
@@ -52,121 +99,304 @@ func (r *Requester) ProcessReservationsActions() error {
 		default:
 			fmt.Printf("\n\nWrong menu item!\n")
 		}
+
+		// сессия закончилась - выходим, меню читателя вернет на вход
+		if errors.Is(err, client.ErrSessionExpired) {
+			return nil
+		}
 	}
 }
 
-func (r *Requester) ViewReservations() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
+// ViewReservations выводит брони читателя с учетом фильтров. Номера строк
+// всегда соответствуют полному списку, поэтому не меняются от фильтров
+func (r *Requester) ViewReservations(ctx context.Context) error {
+	var query search.ReservationQuery
+	if err := r.cache.Get(reservationFiltersKey, &query); err != nil {
 		return err
 	}
 
-	var reservationsID []uuid.UUID
-	if err := r.cache.Get(reservationsKey, &reservationsID); err != nil {
+	reservations, err := r.client.ListReservations(ctx)
+	if err != nil {
 		return err
 	}
+	r.cache.Set(reservationsKey, reservations)
 
-	request := HTTPRequest{
-		Method: http.MethodGet,
-		URL:    r.baseURL + "/api/reservations",
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Timeout: 10 * time.Second,
+	if err = r.resolveReservationFilters(ctx, &query, reservations); err != nil {
+		return err
+	}
+	shown := query.Apply(reservations)
+	if len(shown) == 0 && len(reservations) > 0 {
+		fmt.Printf("\n\nNo reservations match the filters (%s)\n", query)
+		return nil
 	}
 
-	response, err := SendRequest(request)
+	r.printReservations(ctx, reservations, shown, query)
+
+	return nil
+}
+
+func (r *Requester) filterReservations(ctx context.Context) error {
+	query, err := r.in.ReservationFilters()
 	if err != nil {
 		return err
 	}
+	r.cache.Set(reservationFiltersKey, query)
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
-		}
-		return errors.New(info)
-	}
+	return r.ViewReservations(ctx)
+}
 
-	var reservations []*jsonmodels.ReservationModel
-	if err = json.Unmarshal(response.Body, &reservations); err != nil {
+// sortReservationsByReturnDate сортирует брони по дате возврата; повторный
+// выбор меняет порядок на обратный
+func (r *Requester) sortReservationsByReturnDate(ctx context.Context) error {
+	var query search.ReservationQuery
+	if err := r.cache.Get(reservationFiltersKey, &query); err != nil {
 		return err
 	}
 
-	printReservations(reservations)
-	copyReservationIDsToArray(&reservationsID, reservations)
-	r.cache.Set(reservationsKey, reservationsID)
+	query.Desc = query.SortBy == search.SortByReturnDate && !query.Desc
+	query.SortBy = search.SortByReturnDate
+	r.cache.Set(reservationFiltersKey, query)
 
-	return nil
+	return r.ViewReservations(ctx)
 }
 
-func (r *Requester) UpdateReservation() error {
-	var tokens dto.ReaderTokensDTO
-	if err := r.cache.Get(tokensKey, &tokens); err != nil {
+// ExtendReservation запрашивает бронь и продлевает ее
+func (r *Requester) ExtendReservation(ctx context.Context) error {
+	selected, err := r.selectReservation()
+	if err != nil {
 		return err
 	}
 
-	var reservationsID []uuid.UUID
-	if err := r.cache.Get(reservationsKey, &reservationsID); err != nil {
+	return r.confirmExtension(ctx, selected)
+}
+
+// confirmExtension продлевает бронь после подтверждения с новой датой возврата
+func (r *Requester) confirmExtension(ctx context.Context, selected selection) error {
+	// состояние могло измениться с последнего просмотра
+	reservation, err := r.client.GetReservation(ctx, selected.id)
+	if err != nil {
+		return err
+	}
+	books := r.lookupBooks(ctx, []uuid.UUID{reservation.BookID})
+	if err = checkReservationAction(reservation, books[reservation.BookID], actionExtend); err != nil {
 		return err
 	}
 
-	num, err := input.ReservationNumber()
+	question := fmt.Sprintf("Extend reservation %s? The book will be due on %s",
+		r.reservationLabel(reservation, selected.num), formatDate(extendedReturnDate(reservation)))
+	confirmed, err := r.in.Confirm(question)
 	if err != nil {
 		return err
 	}
+	if !confirmed {
+		fmt.Printf("\n\nReservation was not changed\n")
+		return nil
+	}
+
+	return r.extendReservation(ctx, reservation)
+}
+
+func (r *Requester) extendReservation(ctx context.Context, reservation *jsonmodels.ReservationModel) error {
+	if err := r.client.ExtendReservation(ctx, reservation.ID); err != nil {
+		return err
+	}
 
-	if num > len(reservationsID) || num < 0 {
-		return errors.New("reservation number out of range")
+	// дату возврата записывает сервер; если бронь не удалось перечитать,
+	// выводится ожидаемая
+	returnDate := extendedReturnDate(reservation)
+	if updated, err := r.client.GetReservation(ctx, reservation.ID); err == nil {
+		returnDate = updated.ReturnDate
 	}
 
-	reservationID := reservationsID[num]
+	fmt.Printf("\n\nReservation extended, the book is due on %s\n", formatDate(returnDate))
+
+	return nil
+}
 
-	request := HTTPRequest{
-		Method: http.MethodPut,
-		URL:    r.baseURL + fmt.Sprintf("/api/reservations/%s", reservationID.String()),
-		Headers: map[string]string{
-			"Content-Type":  "application/json",
-			"Authorization": fmt.Sprintf("Bearer %s", tokens.AccessToken),
-		},
-		Body:    reservationID,
-		Timeout: 10 * time.Second,
+// selectReservation запрашивает бронь из последнего просмотренного списка
+func (r *Requester) selectReservation() (selection, error) {
+	var reservations []*jsonmodels.ReservationModel
+	if err := r.cache.Get(reservationsKey, &reservations); err != nil {
+		return selection{}, err
 	}
 
-	response, err := SendRequest(request)
+	ref, err := r.in.ReservationRef()
 	if err != nil {
-		return err
+		return selection{}, err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		var info string
-		if err = json.Unmarshal(response.Body, &info); err != nil {
-			return err
+	return resolveRef(reservationIDs(reservations), ref, "reservation")
+}
+
+func reservationIDs(reservations []*jsonmodels.ReservationModel) []uuid.UUID {
+	ids := make([]uuid.UUID, len(reservations))
+	for i, reservation := range reservations {
+		ids[i] = reservation.ID
+	}
+
+	return ids
+}
+
+// checkReservationAction проверяет, что сервер примет действие с бронью: его
+// допускает состояние брони, а продлить можно только бронь на обычную книгу.
+// book - книга брони, nil если она неизвестна
+func checkReservationAction(reservation *jsonmodels.ReservationModel, book *jsonmodels.BookModel, action string) error {
+	if !slices.Contains(reservationActions[reservation.State], action) {
+		allowed := strings.Join(reservationActions[reservation.State], ", ")
+		if allowed == "" {
+			allowed = "none"
 		}
-		return errors.New(info)
+		return fmt.Errorf("cannot %s a reservation in state %s (allowed: %s)", action, reservation.State, allowed)
 	}
 
-	fmt.Printf("\n\nReservation successfully updated!\n")
+	if action == actionExtend && book != nil && book.Rarity != bookRarityCommon {
+		return fmt.Errorf("cannot extend a reservation of a %s book, only %s books can be extended",
+			book.Rarity, bookRarityCommon)
+	}
 
 	return nil
 }
 
-func printReservations(reservations []*jsonmodels.ReservationModel) {
+// allowedActions - действия с бронью, которые примет сервер
+func allowedActions(reservation *jsonmodels.ReservationModel, book *jsonmodels.BookModel) []string {
+	var actions []string
+	for _, action := range reservationActions[reservation.State] {
+		if checkReservationAction(reservation, book, action) == nil {
+			actions = append(actions, action)
+		}
+	}
+
+	return actions
+}
+
+// extendedReturnDate - дата возврата после продления
+func extendedReturnDate(reservation *jsonmodels.ReservationModel) time.Time {
+	return reservation.ReturnDate.AddDate(0, 0, reservationExtensionDays)
+}
+
+// reservationLabel - номер брони (или начало ID) и название книги, если оно
+// уже известно
+func (r *Requester) reservationLabel(reservation *jsonmodels.ReservationModel, num int) string {
+	label := shortID(reservation.ID)
+	if num >= 0 {
+		label = fmt.Sprintf("№%d", num)
+	}
+
+	if book, ok := r.knownBook(reservation.BookID); ok {
+		label += fmt.Sprintf(" (%q)", book.Title)
+	}
+
+	return label
+}
+
+func formatDate(date time.Time) string {
+	return date.Format(time.DateOnly)
+}
+
+func (r *Requester) printReservations(ctx context.Context, all, shown []*jsonmodels.ReservationModel, query search.ReservationQuery) {
+	title := "Reservations"
+	if query.IsSet() {
+		title = fmt.Sprintf("Reservations (%s)", query)
+	}
+
+	r.printReservationTable(ctx, title, shown, reservationNumbers(all, shown), false)
+}
+
+// reservationNumbers возвращает номера показанных броней в полном списке
+func reservationNumbers(all, shown []*jsonmodels.ReservationModel) []int {
+	nums := make([]int, len(shown))
+	for i, reservation := range shown {
+		nums[i] = slices.Index(all, reservation)
+	}
+
+	return nums
+}
+
+// reservationView - бронь вместе с книгой для машиночитаемых форматов
+type reservationView struct {
+	*jsonmodels.ReservationModel
+	BookTitle  string `json:"book_title,omitempty"`
+	BookAuthor string `json:"book_author,omitempty"`
+}
+
+// printReservationTable выводит брони с названием и автором книги и
+// доступными действиями; nums - номера строк, nil - по порядку. Книга,
+// которую не удалось получить, выводится прочерком
+func (r *Requester) printReservationTable(
+	ctx context.Context,
+	title string,
+	reservations []*jsonmodels.ReservationModel,
+	nums []int,
+	withIDs bool,
+) {
+	books := r.lookupBooks(ctx, reservationBookIDs(reservations))
+
 	t := table.NewWriter()
-	t.SetTitle("Reservations")
+	t.SetTitle(title)
 	t.SetStyle(table.StyleBold)
 	t.Style().Format.Header = text.FormatTitle
-	t.AppendHeader(table.Row{"No.", "Issue Date", "Return Date", "State"})
 
-	for i, r := range reservations {
-		t.AppendRow(table.Row{i, r.IssueDate.Format("2006-01-02"), r.ReturnDate.Format("2006-01-02"), r.State})
+	withIDs = withIDs || r.output == output.CSV || r.output == output.Markdown
+
+	header := table.Row{"No.", "Book", "Author", "Issue Date", "Return Date", "State", "Actions"}
+	if withIDs {
+		header = append(header, "ID", "Book ID")
 	}
-	fmt.Println(t.Render())
+	t.AppendHeader(header)
+
+	t.SetColumnConfigs([]table.ColumnConfig{
+		{Name: "Book", WidthMax: 40},
+		{Name: "Author", WidthMax: 30},
+	})
+
+	for i, reservation := range reservations {
+		num := i
+		if nums != nil {
+			num = nums[i]
+		}
+
+		bookTitle, bookAuthor := "-", "-"
+		if book, ok := books[reservation.BookID]; ok {
+			bookTitle, bookAuthor = book.Title, book.Author
+		}
+
+		row := table.Row{
+			num,
+			bookTitle,
+			bookAuthor,
+			formatDate(reservation.IssueDate),
+			formatDate(reservation.ReturnDate),
+			reservation.State,
+			strings.Join(allowedActions(reservation, books[reservation.BookID]), ", "),
+		}
+		if withIDs {
+			row = append(row, reservation.ID, reservation.BookID)
+		}
+		t.AppendRow(row)
+	}
+	r.render(t, r.reservationViews(reservations, books))
 }
 
-func copyReservationIDsToArray(reservationIDs *[]uuid.UUID, reservations []*jsonmodels.ReservationModel) {
-	for _, reservation := range reservations {
-		*reservationIDs = append(*reservationIDs, reservation.ID)
+func (r *Requester) reservationViews(
+	reservations []*jsonmodels.ReservationModel,
+	books map[uuid.UUID]*jsonmodels.BookModel,
+) []reservationView {
+	views := make([]reservationView, len(reservations))
+	for i, reservation := range reservations {
+		views[i] = reservationView{ReservationModel: reservation}
+		if book, ok := books[reservation.BookID]; ok {
+			views[i].BookTitle, views[i].BookAuthor = book.Title, book.Author
+		}
 	}
+
+	return views
+}
+
+func reservationBookIDs(reservations []*jsonmodels.ReservationModel) []uuid.UUID {
+	ids := make([]uuid.UUID, len(reservations))
+	for i, reservation := range reservations {
+		ids[i] = reservation.BookID
+	}
+
+	return ids
 }
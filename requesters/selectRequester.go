@@ -0,0 +1,204 @@
+package requesters
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	jsonmodels "github.com/nikitalystsev/BookSmart-web-api/core/models"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// shortIDLen - сколько первых символов ID показывается в таблице каталога
+	shortIDLen = 8
+	// minIDPrefixLen - самое короткое начало ID, по которому ищется объект
+	minIDPrefixLen = 4
+)
+
+// selection - выбранный объект: ID и его номер в выдаче (-1, если объекта
+// нет среди показанных)
+type selection struct {
+	id  uuid.UUID
+	num int
+}
+
+// selectBook запрашивает книгу: номер строки из выдачи, начало ID или полный ID
+func (r *Requester) selectBook() (selection, error) {
+	var bookPagesID []uuid.UUID
+	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
+		return selection{}, err
+	}
+
+	ref, err := r.in.BookRef()
+	if err != nil {
+		return selection{}, err
+	}
+
+	return resolveBook(bookPagesID, ref)
+}
+
+// resolveBook находит книгу среди просмотренных
+func resolveBook(bookPagesID []uuid.UUID, ref string) (selection, error) {
+	return resolveRef(bookPagesID, ref, "book")
+}
+
+// resolveRef находит объект what среди показанных ids. Число - номер строки;
+// если такой строки нет, оно, как и любая другая ссылка, сравнивается с
+// началом ID
+func resolveRef(ids []uuid.UUID, ref, what string) (selection, error) {
+	ref = strings.ToLower(strings.TrimSpace(ref))
+
+	if id, err := uuid.Parse(ref); err == nil {
+		return selection{id: id, num: slices.Index(ids, id)}, nil
+	}
+
+	num, err := strconv.Atoi(ref)
+	isNumber := err == nil
+	if isNumber && num >= 0 && num < len(ids) && ids[num] != uuid.Nil {
+		return selection{id: ids[num], num: num}, nil
+	}
+
+	if len(ref) < minIDPrefixLen {
+		if isNumber {
+			return selection{}, fmt.Errorf("%s number out of range", what)
+		}
+		return selection{}, fmt.Errorf("%s ID prefix must be at least %d characters long", what, minIDPrefixLen)
+	}
+
+	found, matches := selection{num: -1}, 0
+	for i, id := range ids {
+		if id != uuid.Nil && strings.HasPrefix(id.String(), ref) {
+			found = selection{id: id, num: i}
+			matches++
+		}
+	}
+
+	switch {
+	case matches == 1:
+		return found, nil
+	case matches > 1:
+		return selection{}, fmt.Errorf("ID prefix %q matches %d %ss, type more characters", ref, matches, what)
+	case isNumber:
+		return selection{}, fmt.Errorf("%s number out of range", what)
+	default:
+		return selection{}, fmt.Errorf("no %s with ID starting with %q among the shown ones", what, ref)
+	}
+}
+
+func shortID(id uuid.UUID) string {
+	return id.String()[:shortIDLen]
+}
+
+// selectBooks запрашивает несколько книг: номера строк, диапазоны номеров и
+// ID через запятую, например 1,3,5-8
+func (r *Requester) selectBooks() ([]selection, error) {
+	var bookPagesID []uuid.UUID
+	if err := r.cache.Get(booksKey, &bookPagesID); err != nil {
+		return nil, err
+	}
+
+	refs, err := r.in.BookRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveBooks(bookPagesID, refs)
+}
+
+// resolveBooks разбирает список ссылок на книги; повторы отбрасываются
+func resolveBooks(bookPagesID []uuid.UUID, refs string) ([]selection, error) {
+	var (
+		selections []selection
+		seen       = make(map[uuid.UUID]bool)
+	)
+
+	add := func(ref string) error {
+		selection, err := resolveBook(bookPagesID, ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+		if !seen[selection.id] {
+			seen[selection.id] = true
+			selections = append(selections, selection)
+		}
+		return nil
+	}
+
+	for _, ref := range strings.Split(refs, ",") {
+		if ref = strings.TrimSpace(ref); ref == "" {
+			continue
+		}
+
+		from, to, isRange := rowRange(ref)
+		if !isRange {
+			if err := add(ref); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if from > to || to >= len(bookPagesID) {
+			return nil, fmt.Errorf("%s: book number out of range", ref)
+		}
+		for num := from; num <= to; num++ {
+			if err := add(strconv.Itoa(num)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(selections) == 0 {
+		return nil, errors.New("no books selected")
+	}
+
+	return selections, nil
+}
+
+// rowRange разбирает диапазон номеров строк "from-to". Начало ID с дефисом
+// диапазоном не считается: обе его части должны быть числами
+func rowRange(ref string) (int, int, bool) {
+	fromStr, toStr, ok := strings.Cut(ref, "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	from, err := strconv.Atoi(strings.TrimSpace(fromStr))
+	if err != nil {
+		return 0, 0, false
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(toStr))
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return from, to, true
+}
+
+// viewedBook возвращает книгу с просмотренных страниц
+func (r *Requester) viewedBook(bookID uuid.UUID) (*jsonmodels.BookModel, bool) {
+	var models map[uuid.UUID]*jsonmodels.BookModel
+	if err := r.cache.Get(bookModelsKey, &models); err != nil {
+		return nil, false
+	}
+
+	book, ok := models[bookID]
+
+	return book, ok
+}
+
+// bookTitle возвращает название книги с просмотренных страниц или из
+// избранного, а если ее там нет - ID
+func (r *Requester) bookTitle(bookID uuid.UUID) string {
+	if book, ok := r.viewedBook(bookID); ok {
+		return book.Title
+	}
+
+	var titles map[uuid.UUID]string
+	if err := r.cache.Get(favoriteTitlesKey, &titles); err == nil && titles[bookID] != "" {
+		return titles[bookID]
+	}
+
+	return bookID.String()
+}
@@ -0,0 +1,114 @@
+package requesters
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"testing"
+)
+
+var (
+	bookA = uuid.MustParse("1111aaaa-0000-4000-8000-000000000001")
+	bookB = uuid.MustParse("1111bbbb-0000-4000-8000-000000000002")
+	bookC = uuid.MustParse("2222cccc-0000-4000-8000-000000000003")
+	bookD = uuid.MustParse("12340000-0000-4000-8000-000000000004")
+	// bookE есть в каталоге, но не среди показанных
+	bookE = uuid.MustParse("99999999-0000-4000-8000-000000000005")
+)
+
+// shownBooks - просмотренные строки каталога; строка 3 - непросмотренная страница
+func shownBooks() []uuid.UUID {
+	return []uuid.UUID{bookA, bookB, bookC, uuid.Nil, bookD}
+}
+
+func TestResolveRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		wantID  uuid.UUID
+		wantNum int
+		wantErr string
+	}{
+		{name: "row number", ref: "2", wantID: bookC, wantNum: 2},
+		{name: "first row", ref: " 0 ", wantID: bookA, wantNum: 0},
+		{name: "full ID", ref: bookB.String(), wantID: bookB, wantNum: 1},
+		{name: "full ID in upper case", ref: strings.ToUpper(bookC.String()), wantID: bookC, wantNum: 2},
+		{name: "full ID not shown", ref: bookE.String(), wantID: bookE, wantNum: -1},
+		{name: "ID prefix", ref: "2222", wantID: bookC, wantNum: 2},
+		{name: "short ID as in the table", ref: "1111BBBB", wantID: bookB, wantNum: 1},
+		{name: "number that is not a row is an ID prefix", ref: "1234", wantID: bookD, wantNum: 4},
+		{name: "ambiguous prefix", ref: "1111", wantErr: "matches 2 books"},
+		{name: "unknown prefix", ref: "abcd", wantErr: "no book with ID starting with"},
+		{name: "prefix too short", ref: "22c", wantErr: "at least 4 characters"},
+		{name: "row out of range", ref: "7", wantErr: "number out of range"},
+		{name: "negative row", ref: "-1", wantErr: "number out of range"},
+		{name: "row of an unseen page", ref: "3", wantErr: "number out of range"},
+		{name: "long number out of range", ref: "5555", wantErr: "number out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveRef(shownBooks(), tt.ref, "book")
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveRef(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveRef(%q): %v", tt.ref, err)
+			}
+			if got.id != tt.wantID || got.num != tt.wantNum {
+				t.Errorf("resolveRef(%q) = %s #%d, want %s #%d", tt.ref, got.id, got.num, tt.wantID, tt.wantNum)
+			}
+		})
+	}
+}
+
+func TestResolveBooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		refs    string
+		want    []uuid.UUID
+		wantErr string
+	}{
+		{name: "single row", refs: "1", want: []uuid.UUID{bookB}},
+		{name: "list", refs: "2, 0", want: []uuid.UUID{bookC, bookA}},
+		{name: "range", refs: "0-2", want: []uuid.UUID{bookA, bookB, bookC}},
+		{name: "range with spaces", refs: "1 - 2", want: []uuid.UUID{bookB, bookC}},
+		{name: "duplicates are dropped", refs: "1,0-2,1111bbbb", want: []uuid.UUID{bookB, bookA, bookC}},
+		{name: "rows and ID prefixes", refs: "2222,4", want: []uuid.UUID{bookC, bookD}},
+		{name: "empty items are skipped", refs: ",1,,", want: []uuid.UUID{bookB}},
+		{name: "full ID with dashes is not a range", refs: bookA.String(), want: []uuid.UUID{bookA}},
+		{name: "reversed range", refs: "2-1", wantErr: "out of range"},
+		{name: "range past the end", refs: "3-9", wantErr: "out of range"},
+		{name: "range over an unseen page", refs: "2-4", wantErr: "3: book number out of range"},
+		{name: "ambiguous prefix", refs: "0,1111", wantErr: "1111: ID prefix"},
+		{name: "nothing selected", refs: " , ", wantErr: "no books selected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBooks(shownBooks(), tt.refs)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveBooks(%q) error = %v, want %q", tt.refs, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveBooks(%q): %v", tt.refs, err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("resolveBooks(%q) selected %d books, want %d", tt.refs, len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].id != tt.want[i] {
+					t.Errorf("resolveBooks(%q)[%d] = %s, want %s", tt.refs, i, got[i].id, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package requesters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var errSessionStoreDisabled = errors.New("session store is disabled, run with --session-store to enable it")
+
+// expiredSessionError - сохраненная сессия истекла и удалена. Для вызывающего
+// кода это то же, что отсутствие сессии
+type expiredSessionError struct {
+	reason string
+}
+
+func (e expiredSessionError) Error() string {
+	return e.reason + ", please sign in again"
+}
+
+func (e expiredSessionError) Is(target error) bool {
+	return target == session.ErrNoSession
+}
+
+// openSessionStore открывает хранилище сессии, если оно включено. Парольная
+// фраза берется из окружения, а если ее там нет - запрашивается у пользователя
+func (r *Requester) openSessionStore() error {
+	if !r.sessionStore {
+		return nil
+	}
+
+	if r.sessionPassphrase == "" {
+		passphrase, err := r.in.Passphrase()
+		if err != nil {
+			return err
+		}
+		if passphrase == "" {
+			return errors.New("empty passphrase, the session will not be saved")
+		}
+		r.sessionPassphrase = passphrase
+	}
+
+	r.setSessionStore(r.sessionStoreFor(""))
+
+	return nil
+}
+
+// sessionStoreFor возвращает хранилище сессии профиля name ("" - без профиля).
+// Если сохранение сессий выключено, токены профиля хранятся только в памяти,
+// а без профиля не хранятся вовсе
+func (r *Requester) sessionStoreFor(name string) session.ISessionStore {
+	if store, ok := r.profileSessions[name]; ok {
+		return store
+	}
+
+	var store session.ISessionStore
+	switch {
+	case r.sessionStore && r.sessionPassphrase != "" && name == "":
+		store = session.NewFileStore(r.sessionFile, r.sessionPassphrase)
+	case r.sessionStore && r.sessionPassphrase != "":
+		base := strings.TrimSuffix(r.sessionFile, filepath.Ext(r.sessionFile))
+		store = session.NewFileStore(base+"-"+name+".json", r.sessionPassphrase)
+	case name != "":
+		store = session.NewMemoryStore()
+	default:
+		return nil
+	}
+	r.profileSessions[name] = store
+
+	return store
+}
+
+// ResumeSession продолжает сохраненную сессию и открывает меню читателя
+// или администратора
+func (r *Requester) ResumeSession(ctx context.Context) error {
+	role, err := r.restoreSession(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nSaved session resumed!\n")
+
+	return r.enterSession(ctx, role)
+}
+
+// restoreSession восстанавливает сохраненные токены и проверяет их обновлением.
+// Истекшую или отклоненную сервером сессию удаляет и возвращает
+// expiredSessionError, которая совпадает с ErrNoSession
+func (r *Requester) restoreSession(ctx context.Context) (string, error) {
+	if r.sessions == nil {
+		return "", errSessionStoreDisabled
+	}
+
+	saved, err := r.sessions.Load()
+	if err != nil {
+		return "", err
+	}
+
+	if time.Since(saved.IssuedAt) >= r.refreshTokenTTL {
+		return "", r.forgetExpired("saved session has expired")
+	}
+
+	r.setRole(saved.Role)
+	r.client.RestoreTokens(saved.Tokens, saved.IssuedAt)
+
+	if err = interruptible(ctx, r.Refresh); err != nil {
+		r.client.SignOut()
+		if errors.Is(err, client.ErrSessionExpired) {
+			return "", r.forgetExpired("saved session is no longer valid")
+		}
+		return "", err
+	}
+
+	return saved.Role, nil
+}
+
+func (r *Requester) forgetExpired(reason string) error {
+	if err := r.sessions.Delete(); err != nil {
+		return err
+	}
+
+	return expiredSessionError{reason: reason}
+}
+
+// enterSession открывает меню, соответствующее роли вошедшего пользователя
+func (r *Requester) enterSession(ctx context.Context, role string) error {
+	if role == session.RoleAdmin {
+		return r.adminSession(ctx)
+	}
+
+	return r.readerSession(ctx)
+}
+
+// ForgetSession удаляет сохраненную сессию; текущий вход при этом не завершается
+func (r *Requester) ForgetSession() error {
+	if r.sessions == nil {
+		return errSessionStoreDisabled
+	}
+
+	if err := r.sessions.Delete(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\nSaved session forgotten!\n")
+
+	return nil
+}
+
+// logOut завершает вход и удаляет сохраненную сессию, чтобы следующий запуск
+// не вошел в аккаунт снова
+func (r *Requester) logOut() {
+	r.client.SignOut()
+
+	if r.sessions == nil {
+		return
+	}
+	if err := r.sessions.Delete(); err != nil {
+		fmt.Printf("\n\nerror forgetting session: %v\n", err)
+	}
+}
+
+// saveSession сохраняет каждую новую пару токенов. После выхода токены
+// пустые и ничего не сохраняется - сессию удаляет logOut
+func (r *Requester) saveSession(tokens dto.ReaderTokensDTO, issuedAt time.Time) {
+	r.sessionMu.RLock()
+	store, role := r.sessions, r.role
+	r.sessionMu.RUnlock()
+
+	if store == nil || tokens.AccessToken == "" || role == "" {
+		return
+	}
+
+	err := store.Save(session.Session{
+		Tokens:   tokens,
+		IssuedAt: issuedAt,
+		Role:     role,
+	})
+	if err != nil {
+		fmt.Printf("\n\nerror saving session: %v\n", err)
+	}
+}
+
+// setSessionStore меняет хранилище сессии текущего профиля
+func (r *Requester) setSessionStore(store session.ISessionStore) {
+	r.sessionMu.Lock()
+	defer r.sessionMu.Unlock()
+	r.sessions = store
+}
+
+// setRole запоминает роль, под которой выполнен вход
+func (r *Requester) setRole(role string) {
+	r.sessionMu.Lock()
+	defer r.sessionMu.Unlock()
+	r.role = role
+}
@@ -0,0 +1,62 @@
+package requesters
+
+import (
+	"context"
+	"errors"
+	"github.com/nikitalystsev/BookSmart-services/core/dto"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/client"
+	"github.com/nikitalystsev/BookSmart-tech-ui/pkg/session"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLogOutForgetsSavedSession(t *testing.T) {
+	store := session.NewFileStore(filepath.Join(t.TempDir(), "session.json"), "passphrase")
+	err := store.Save(session.Session{
+		Tokens:   dto.ReaderTokensDTO{AccessToken: "access", RefreshToken: "refresh"},
+		IssuedAt: time.Now(),
+		Role:     session.RoleReader,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiClient, err := client.NewClient("http://127.0.0.1:1", client.TransportConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Requester{
+		client:          apiClient,
+		sessions:        store,
+		role:            session.RoleReader,
+		refreshTokenTTL: time.Hour,
+	}
+
+	r.logOut()
+
+	if err = r.ResumeSession(context.Background()); !errors.Is(err, session.ErrNoSession) {
+		t.Fatalf("ResumeSession after logout: got %v, want %v", err, session.ErrNoSession)
+	}
+}
+
+func TestSaveSessionWhileSwitchingRole(t *testing.T) {
+	r := &Requester{}
+	r.setSessionStore(session.NewMemoryStore())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			r.saveSession(dto.ReaderTokensDTO{AccessToken: "access", RefreshToken: "refresh"}, time.Now())
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		r.setRole(session.RoleAdmin)
+		r.setSessionStore(session.NewMemoryStore())
+		r.setRole(session.RoleReader)
+	}
+	<-done
+}